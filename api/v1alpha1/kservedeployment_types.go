@@ -1,6 +1,8 @@
 package v1alpha1
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -16,8 +18,261 @@ type KServeDeploymentSpec struct {
 	// +kubebuilder:default=kserve
 	Namespace string `json:"namespace,omitempty"`
 
+	// ComponentNamespaces overrides the namespace per component. Defaults are
+	// cert-manager -> cert-manager, knative -> knative-serving,
+	// istio -> istio-system and kserve -> Namespace. Resources the upstream
+	// manifests declare in the default namespace are moved to the override.
+	ComponentNamespaces map[string]string `json:"componentNamespaces,omitempty"`
+
 	// Configuration for KServe components
 	Config *KServeConfig `json:"config,omitempty"`
+
+	// PostInstallJobs run once the core install is Ready (migrations, warmups, smoke tests)
+	PostInstallJobs []JobSpec `json:"postInstallJobs,omitempty"`
+
+	// ExtraManifests are applied after the core components (Gateways, ServingRuntimes, dashboards)
+	ExtraManifests []ManifestRef `json:"extraManifests,omitempty"`
+
+	// ApplyStrategy used for manifests (Update, ServerSideApply)
+	// +kubebuilder:validation:Enum=Update;ServerSideApply
+	// +kubebuilder:default=Update
+	ApplyStrategy string `json:"applyStrategy,omitempty"`
+
+	// ForceOwnership takes over fields owned by other field managers instead of
+	// reporting a FieldConflict condition. Only used with ServerSideApply.
+	ForceOwnership bool `json:"forceOwnership,omitempty"`
+
+	// InferenceService configures the InferenceService template deployed with KServe
+	InferenceService *InferenceServiceTemplate `json:"inferenceService,omitempty"`
+
+	// ImagePullSecrets are added to the ServiceAccounts and pod specs of the
+	// applied workloads. Secrets missing from a component namespace are copied
+	// from the KServeDeployment's namespace.
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+
+	// NodeSelector is merged into the pod spec of the component Deployments
+	// (e.g. the KServe controller and webhook) to pin them to a node pool
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are added to the pod spec of the component Deployments
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity replaces the affinity of the component Deployments
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// DefaultRuntime is set as the runtime of applied InferenceServices whose
+	// model doesn't name one. It must be an installed ServingRuntime in the
+	// InferenceService's namespace or a ClusterServingRuntime.
+	DefaultRuntime string `json:"defaultRuntime,omitempty"`
+
+	// DeletionGracePeriod delays tearing down the managed resources after the
+	// KServeDeployment is deleted, so in-flight inference traffic can drain.
+	// The deployment reports Terminating in the meantime.
+	DeletionGracePeriod *metav1.Duration `json:"deletionGracePeriod,omitempty"`
+
+	// AdoptExisting takes over a KServe install that wasn't made by the
+	// operator instead of refusing to install over it
+	AdoptExisting bool `json:"adoptExisting,omitempty"`
+
+	// FeatureFlags toggle KServe features by name (see KnownFeatureFlags).
+	// They are written into KServe's inferenceservice-config ConfigMap on top
+	// of its base configuration.
+	FeatureFlags map[string]bool `json:"featureFlags,omitempty"`
+
+	// AllowDowngrade permits a Version older than the installed one
+	AllowDowngrade bool `json:"allowDowngrade,omitempty"`
+
+	// ReconcileSchedule is a cron expression (UTC, e.g. "0 3 * * *" or
+	// "@daily") at which every resource is re-applied, even unchanged ones,
+	// independent of the resync period
+	ReconcileSchedule string `json:"reconcileSchedule,omitempty"`
+
+	// AtomicInstall makes each component all-or-nothing: if any of its
+	// resources fails to apply, the resources it created are deleted again
+	AtomicInstall bool `json:"atomicInstall,omitempty"`
+
+	// PriorityClassName is set on the pods of the control-plane workloads
+	// (controllers and webhooks) so they aren't preempted under pressure. The
+	// PriorityClass must exist.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// RequireApproval holds every new spec in the Pending phase until it is
+	// approved with the platform.ai-platform.io/approve annotation
+	RequireApproval bool `json:"requireApproval,omitempty"`
+
+	// RuntimeVerification checks that the ServingRuntimes the operator
+	// applies are usable, not just applied. Unset disables it.
+	RuntimeVerification *RuntimeVerificationSpec `json:"runtimeVerification,omitempty"`
+
+	// InvalidSpecPolicy decides what happens to the running install when the
+	// spec fails validation: Retain keeps the last good install untouched,
+	// TearDown deletes it. Either way the SpecInvalid condition is set.
+	// +kubebuilder:validation:Enum=Retain;TearDown
+	// +kubebuilder:default=Retain
+	InvalidSpecPolicy string `json:"invalidSpecPolicy,omitempty"`
+
+	// ManifestValidation checks the manifests against the cluster's OpenAPI
+	// schema before anything is applied. Warn reports unknown fields and type
+	// mismatches in the ManifestSchemaMismatch condition; Error also fails
+	// the reconcile.
+	// +kubebuilder:validation:Enum=Disabled;Warn;Error
+	// +kubebuilder:default=Disabled
+	ManifestValidation string `json:"manifestValidation,omitempty"`
+
+	// InferenceServiceReadiness decides whether the managed InferenceServices
+	// gate the Ready phase. Strict reports Ready only once all of them are
+	// Ready; BestEffort only reports them in the InferenceServicesReady
+	// condition.
+	// +kubebuilder:validation:Enum=Strict;BestEffort
+	// +kubebuilder:default=Strict
+	InferenceServiceReadiness string `json:"inferenceServiceReadiness,omitempty"`
+
+	// DeleteNamespaceOnCleanup deletes the namespaces the operator created
+	// (status.createdNamespaces) when the KServeDeployment is deleted. A
+	// namespace is kept if another KServeDeployment uses it or it contains
+	// resources the operator doesn't manage; pre-existing namespaces are
+	// never deleted.
+	DeleteNamespaceOnCleanup bool `json:"deleteNamespaceOnCleanup,omitempty"`
+
+	// DeleteCRDsOnCleanup lets the operator delete the CustomResourceDefinitions
+	// it applied, which deletes every custom resource of their kinds, when the
+	// KServeDeployment is deleted, torn down, rolled back or pruned. They are
+	// left in place by default.
+	DeleteCRDsOnCleanup bool `json:"deleteCRDsOnCleanup,omitempty"`
+
+	// PinManifests snapshots the rendered manifests into a ConfigMap once the
+	// install is first Ready and from then on applies the snapshot instead of
+	// fetching the manifest sources. A version bump or the
+	// platform.ai-platform.io/resnapshot annotation reconciles from the
+	// sources again and replaces the snapshot. Other spec changes that alter
+	// the manifests only take effect with a new snapshot.
+	PinManifests bool `json:"pinManifests,omitempty"`
+
+	// StorageCredentials gives the storage initializer of applied
+	// InferenceServices access to the model storage. The operator copies the
+	// referenced Secret into each InferenceService namespace with KServe's
+	// storage annotations and creates a ServiceAccount that references it.
+	StorageCredentials *StorageCredentialsSpec `json:"storageCredentials,omitempty"`
+}
+
+// StorageCredentialsSpec references the credentials InferenceServices pull
+// their models with
+type StorageCredentialsSpec struct {
+	// SecretName is the Secret in the KServeDeployment's namespace holding
+	// the credentials, e.g. AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY for
+	// S3 or gcloud-application-credentials.json for GCS
+	SecretName string `json:"secretName"`
+
+	// ServiceAccountName of the ServiceAccount the operator creates. It is
+	// set on applied InferenceServices whose predictor doesn't name a
+	// ServiceAccount. Defaults to <name>-storage.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// AllowedNamespaces besides the KServeDeployment's own that rendered
+	// InferenceServices may run in, and so receive a copy of the credentials.
+	// An InferenceService in any other namespace fails provisioning.
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	// S3 configures access to an S3-compatible endpoint
+	S3 *S3StorageSpec `json:"s3,omitempty"`
+}
+
+// S3StorageSpec holds the S3 settings KServe reads from the credentials
+// Secret's annotations
+type S3StorageSpec struct {
+	// Endpoint of the S3 API, e.g. s3.amazonaws.com or minio.minio:9000
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Region of the bucket
+	Region string `json:"region,omitempty"`
+
+	// UseHTTPS connects to the endpoint over HTTPS. Defaults to true.
+	UseHTTPS *bool `json:"useHttps,omitempty"`
+
+	// VerifySSL verifies the endpoint's certificate. Defaults to true.
+	VerifySSL *bool `json:"verifySsl,omitempty"`
+}
+
+// RuntimeVerificationSpec configures ServingRuntime verification. Every
+// runtime's container images are pulled by a short-lived probe Pod.
+type RuntimeVerificationSpec struct {
+	// ProbeModels maps a runtime name to the storageUri of a small model. A
+	// probe InferenceService serving it with the runtime must become Ready
+	// for the runtime to be verified. Runtimes without an entry are only
+	// checked for pullable images.
+	ProbeModels map[string]string `json:"probeModels,omitempty"`
+}
+
+// KnownFeatureFlags are the KServe features FeatureFlags can toggle
+var KnownFeatureFlags = []string{
+	"directPvcVolumeMount",
+	"ingressCreation",
+	"istioVirtualHost",
+	"metricAggregation",
+	"prometheusScraping",
+}
+
+// InferenceServiceTemplate selects and parameterizes the InferenceService
+// manifest. Templates are Go text/templates with access to the deployment's
+// name, namespace, KServe namespace, version, ingress domain, parameters and spec.
+type InferenceServiceTemplate struct {
+	// TemplatePath of the InferenceService template, which must be in the
+	// operator's config/operand directory
+	// +kubebuilder:default="config/operand/gemma2-inferenceservice.yaml"
+	TemplatePath string `json:"templatePath,omitempty"`
+
+	// Parameters available to the template as .Parameters (e.g. model, name)
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// ManifestRef points at a manifest to apply. Exactly one source must be set.
+type ManifestRef struct {
+	// Name identifies the manifest in status
+	Name string `json:"name"`
+
+	// URL to fetch the manifest from
+	URL string `json:"url,omitempty"`
+
+	// Checksum is the expected hex-encoded SHA-256 of the manifest fetched
+	// from URL. A manifest that doesn't match is not applied.
+	Checksum string `json:"checksum,omitempty"`
+
+	// ConfigMapRef reads the manifest from a key of a ConfigMap
+	ConfigMapRef *ConfigMapKeyReference `json:"configMapRef,omitempty"`
+}
+
+// ConfigMapKeyReference selects a key of a ConfigMap
+type ConfigMapKeyReference struct {
+	// Namespace of the ConfigMap. It must be the KServeDeployment's own, so
+	// a KServeDeployment can't apply manifests from namespaces it doesn't
+	// control, and defaults to it.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the ConfigMap
+	Name string `json:"name"`
+
+	// Key holding the manifest YAML
+	Key string `json:"key"`
+}
+
+// JobSpec defines a one-off Job the operator runs after the core install
+type JobSpec struct {
+	// Name of the Job, prefixed with the KServeDeployment name when created
+	Name string `json:"name"`
+
+	// Spec of the Job to create in the KServe namespace
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Spec batchv1.JobSpec `json:"spec"`
+
+	// TimeoutSeconds after which an unfinished Job is treated as failed
+	// +kubebuilder:default=600
+	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
+
+	// RetentionPolicy for completed Jobs (Retain, Delete, DeleteOnSuccess)
+	// +kubebuilder:validation:Enum=Retain;Delete;DeleteOnSuccess
+	// +kubebuilder:default=DeleteOnSuccess
+	RetentionPolicy string `json:"retentionPolicy,omitempty"`
 }
 
 // KServeConfig defines configuration options for KServe
@@ -30,18 +285,24 @@ type KServeConfig struct {
 
 	// EnableKnative for serverless serving
 	EnableKnative bool `json:"enableKnative,omitempty"`
+
+	// NetworkingLayer Knative routes serverless traffic through (Istio,
+	// Kourier). Only valid with EnableKnative; empty means Istio.
+	// +kubebuilder:validation:Enum=Istio;Kourier
+	NetworkingLayer string `json:"networkingLayer,omitempty"`
 }
 
 // KServeDeploymentStatus defines the observed state of KServe deployment
 type KServeDeploymentStatus struct {
-	// Phase of the deployment (Pending, Installing, Ready, Failed)
-	// +kubebuilder:validation:Enum=Pending;Installing;Ready;Failed
+	// Phase of the deployment (Pending, Installing, Ready, Degraded, Failed, Terminating)
+	// +kubebuilder:validation:Enum=Pending;Installing;Ready;Degraded;Failed;Terminating
 	Phase string `json:"phase,omitempty"`
 
 	// Conditions represent the latest available observations
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 
-	// InstalledVersion is the currently installed version
+	// InstalledVersion is the last version that was installed successfully.
+	// A failed install or upgrade leaves it unchanged.
 	InstalledVersion string `json:"installedVersion,omitempty"`
 
 	// InstalledComponents lists the successfully installed components
@@ -49,10 +310,379 @@ type KServeDeploymentStatus struct {
 
 	// LastUpdated timestamp
 	LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
+
+	// PostInstallJobs records the outcome of each post-install Job
+	PostInstallJobs []JobStatus `json:"postInstallJobs,omitempty"`
+
+	// LastReconcileTime is when the most recent reconcile finished
+	LastReconcileTime *metav1.Time `json:"lastReconcileTime,omitempty"`
+
+	// LastReconcileDuration is how long the most recent reconcile took
+	LastReconcileDuration *metav1.Duration `json:"lastReconcileDuration,omitempty"`
+
+	// ExtraManifests records the resources applied from each extra manifest
+	ExtraManifests []ManifestStatus `json:"extraManifests,omitempty"`
+
+	// UpgradeCheckpoint records progress through an install or upgrade that
+	// spans multiple reconciles; it is cleared once the upgrade completes
+	UpgradeCheckpoint *UpgradeCheckpoint `json:"upgradeCheckpoint,omitempty"`
+
+	// ObservedSpecHash is a stable hash of the spec last reconciled. It only
+	// changes when the spec does, so GitOps tools can tell real spec changes
+	// from status churn.
+	ObservedSpecHash string `json:"observedSpecHash,omitempty"`
+
+	// VersionDiff summarizes the resource changes between the installed KServe
+	// version and the one named in the diff-version annotation
+	VersionDiff *VersionDiff `json:"versionDiff,omitempty"`
+
+	// HealthySince is when a Degraded deployment was first seen healthy again;
+	// it is reported Ready once it has stayed healthy for the stabilization period
+	HealthySince *metav1.Time `json:"healthySince,omitempty"`
+
+	// ComponentStatuses records which version of each component was applied
+	// and by which operator build
+	ComponentStatuses []ComponentStatus `json:"componentStatuses,omitempty"`
+
+	// ManagedResources lists every resource the operator applied for this
+	// KServeDeployment. Resources dropped from the desired state are pruned
+	// from it, and deleting the KServeDeployment deletes them.
+	ManagedResources []ResourceRef `json:"managedResources,omitempty"`
+
+	// InstallStartedAt is when the current install began: the first reconcile
+	// of the object, or the first one after a spec change
+	InstallStartedAt *metav1.Time `json:"installStartedAt,omitempty"`
+
+	// ReadyDuration is how long the current install took to become Ready
+	ReadyDuration *metav1.Duration `json:"readyDuration,omitempty"`
+
+	// NextScheduledReconcile is when the ReconcileSchedule next re-applies everything
+	NextScheduledReconcile *metav1.Time `json:"nextScheduledReconcile,omitempty"`
+
+	// InferenceServices lists the InferenceServices the operator applied, with
+	// the deployment metadata stamped on each
+	InferenceServices []InferenceServiceStatus `json:"inferenceServices,omitempty"`
+
+	// Approval records who approved the spec last installed under RequireApproval
+	Approval *ApprovalStatus `json:"approval,omitempty"`
+
+	// ServingRuntimes reports the verification of each applied ServingRuntime
+	// when RuntimeVerification is set
+	ServingRuntimes []ServingRuntimeStatus `json:"servingRuntimes,omitempty"`
+
+	// CreatedNamespaces lists the namespaces the operator created for this
+	// KServeDeployment, as opposed to ones that already existed
+	CreatedNamespaces []string `json:"createdNamespaces,omitempty"`
+
+	// ManifestSnapshot records the snapshot a PinManifests deployment
+	// reconciles toward
+	ManifestSnapshot *ManifestSnapshotStatus `json:"manifestSnapshot,omitempty"`
+
+	// StorageCredentials records where the storage credentials were
+	// provisioned when StorageCredentials is set
+	StorageCredentials *StorageCredentialsStatus `json:"storageCredentials,omitempty"`
+}
+
+// Condition reasons. Every condition the operator sets carries one of these
+// as its reason, so tools can match on it instead of the message, which is
+// free text and may change. The reasons are grouped by what they report:
+//
+//   - Progress of the Ready condition: InstallComplete, Installing,
+//     WaitingForReadiness, Stabilizing, ApprovalRequired, Terminating.
+//   - Why an install failed (Ready is False with phase Failed):
+//     ManifestFetchFailed, ChecksumMismatch, DependencyMissing, ApplyFailed,
+//     QuotaExceeded, SchemaErrors, ValidationFailed, AdoptionDisabled,
+//     ScopeConflict, ProvisioningFailed, ReconcileFailed.
+//   - Why an install is degraded: PostInstallJobFailed, ImagePullBackOff,
+//     ModelLoadFailed.
+//   - Holds that keep the installed version: DowngradeNotAllowed,
+//     WaitingForCanaries.
+//   - The remaining reasons belong to a single auxiliary condition, named
+//     in each constant's comment.
+//
+// ReconcileFailed is the fallback for failures that fit no other reason.
+const (
+	// ReasonInstallComplete: every component is installed and Ready
+	ReasonInstallComplete = "InstallComplete"
+	// ReasonInstalling: the install or upgrade is in progress
+	ReasonInstalling = "Installing"
+	// ReasonWaitingForReadiness: everything is applied, but the KServe
+	// webhook or the InferenceServices aren't serving yet
+	ReasonWaitingForReadiness = "WaitingForReadiness"
+	// ReasonStabilizing: a degraded deployment is healthy again but hasn't
+	// stayed healthy for the stabilization period
+	ReasonStabilizing = "Stabilizing"
+	// ReasonApprovalRequired: the spec waits for approval (Ready,
+	// AwaitingApproval)
+	ReasonApprovalRequired = "ApprovalRequired"
+	// ReasonTerminating: the deployment was deleted and its resources are
+	// removed once the deletion grace period has passed
+	ReasonTerminating = "Terminating"
+
+	// ReasonManifestFetchFailed: a manifest couldn't be downloaded or read
+	ReasonManifestFetchFailed = "ManifestFetchFailed"
+	// ReasonChecksumMismatch: a manifest didn't match its pinned checksum
+	ReasonChecksumMismatch = "ChecksumMismatch"
+	// ReasonDependencyMissing: something the install needs, such as a
+	// PriorityClass or an image pull secret, doesn't exist
+	ReasonDependencyMissing = "DependencyMissing"
+	// ReasonApplyFailed: the manifests couldn't be applied
+	ReasonApplyFailed = "ApplyFailed"
+	// ReasonQuotaExceeded: the workloads won't fit a ResourceQuota (Ready,
+	// InsufficientQuota)
+	ReasonQuotaExceeded = "QuotaExceeded"
+	// ReasonSchemaErrors: the manifests don't match the cluster's API schema
+	// and ManifestValidation is Error (Ready, ManifestSchemaMismatch)
+	ReasonSchemaErrors = "SchemaErrors"
+	// ReasonSchemaWarnings: as SchemaErrors with ManifestValidation Warn
+	// (ManifestSchemaMismatch)
+	ReasonSchemaWarnings = "SchemaWarnings"
+	// ReasonValidationFailed: the spec is invalid (Ready, SpecInvalid)
+	ReasonValidationFailed = "ValidationFailed"
+	// ReasonAdoptionDisabled: a KServe install the operator doesn't manage
+	// exists and AdoptExisting is off (Ready, UnmanagedInstallDetected)
+	ReasonAdoptionDisabled = "AdoptionDisabled"
+	// ReasonScopeConflict: the components can't be installed from a
+	// single-namespace operator
+	ReasonScopeConflict = "ScopeConflict"
+	// ReasonProvisioningFailed: the storage credentials couldn't be
+	// provisioned (Ready, StorageCredentialsReady)
+	ReasonProvisioningFailed = "ProvisioningFailed"
+	// ReasonReconcileFailed: the reconcile failed for another reason
+	ReasonReconcileFailed = "ReconcileFailed"
+
+	// ReasonPostInstallJobFailed: a post-install Job failed
+	ReasonPostInstallJobFailed = "PostInstallJobFailed"
+	// ReasonImagePullBackOff: pods can't pull their images (Ready,
+	// ImagePullFailed)
+	ReasonImagePullBackOff = "ImagePullBackOff"
+	// ReasonModelLoadFailed: an InferenceService failed to load its model
+	// (Ready, InferenceServicesReady)
+	ReasonModelLoadFailed = "ModelLoadFailed"
+
+	// ReasonDowngradeNotAllowed: the spec asks for an older version without
+	// AllowDowngrade (Ready, DowngradeBlocked)
+	ReasonDowngradeNotAllowed = "DowngradeNotAllowed"
+	// ReasonWaitingForCanaries: the upgrade waits for the canaries to become
+	// Ready (Ready, CanaryHold)
+	ReasonWaitingForCanaries = "WaitingForCanaries"
+	// ReasonNoCanaryForVersion: canaries exist but none targets the desired
+	// version, so the upgrade waits until one does (Ready, CanaryHold)
+	ReasonNoCanaryForVersion = "NoCanaryForVersion"
+
+	// ReasonCanaryLabel: the deployment is a canary (Canary)
+	ReasonCanaryLabel = "CanaryLabel"
+	// ReasonCircuitOpen: a manifest source failed repeatedly and isn't
+	// contacted until its cooldown passes (SourceUnavailable)
+	ReasonCircuitOpen = "CircuitOpen"
+	// ReasonFieldManagerConflict: another field manager owns fields the
+	// operator applies (FieldConflict)
+	ReasonFieldManagerConflict = "FieldManagerConflict"
+	// ReasonDryRunSucceeded and ReasonDryRunFailed: the KServe webhook
+	// probe's outcome (WebhookReachable)
+	ReasonDryRunSucceeded = "DryRunSucceeded"
+	ReasonDryRunFailed    = "DryRunFailed"
+	// ReasonPauseConfigMapPresent: the operator-wide pause ConfigMap exists
+	// (GloballyPaused)
+	ReasonPauseConfigMapPresent = "PauseConfigMapPresent"
+	// ReasonLatencyAboveAverage: a manifest source is slower than usual
+	// (SlowManifestSource)
+	ReasonLatencyAboveAverage = "LatencyAboveAverage"
+	// ReasonBudgetExhausted: the operator's retry budget ran out
+	// (RetryBudgetExhausted)
+	ReasonBudgetExhausted = "BudgetExhausted"
+	// ReasonVerified, ReasonVerifying and ReasonVerificationFailed: the
+	// ServingRuntime verification's progress (ServingRuntimesVerified)
+	ReasonVerified           = "Verified"
+	ReasonVerifying          = "Verifying"
+	ReasonVerificationFailed = "VerificationFailed"
+	// ReasonAllReady and ReasonNotReady: whether the managed
+	// InferenceServices are Ready (InferenceServicesReady)
+	ReasonAllReady = "AllReady"
+	ReasonNotReady = "NotReady"
+	// ReasonCredentialsProvisioned: the storage credentials Secret and
+	// ServiceAccount exist in every InferenceService namespace
+	// (StorageCredentialsReady)
+	ReasonCredentialsProvisioned = "CredentialsProvisioned"
+)
+
+// ManifestSnapshotStatus identifies a pinned manifest snapshot
+type ManifestSnapshotStatus struct {
+	// ConfigMap holds the snapshot, in the KServeDeployment's namespace
+	ConfigMap string `json:"configMap"`
+
+	// Version is the KServe version the snapshot was taken of
+	Version string `json:"version"`
+
+	// Checksum is the SHA-256 of the snapshotted manifests. A snapshot that
+	// no longer matches it is not applied.
+	Checksum string `json:"checksum"`
+
+	// Resources is the number of resources in the snapshot
+	Resources int `json:"resources"`
+
+	// TakenAt is when the snapshot was taken
+	TakenAt metav1.Time `json:"takenAt"`
+}
+
+// StorageCredentialsStatus records the provisioned storage credentials
+type StorageCredentialsStatus struct {
+	// ServiceAccountName of the ServiceAccount set on InferenceServices
+	ServiceAccountName string `json:"serviceAccountName"`
+
+	// SecretName of the operator's copy of the credentials Secret
+	SecretName string `json:"secretName"`
+
+	// Namespaces the ServiceAccount and Secret were provisioned in
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// ServingRuntimeStatus records the verification of a ServingRuntime or
+// ClusterServingRuntime
+type ServingRuntimeStatus struct {
+	// Name of the runtime
+	Name string `json:"name"`
+
+	// Namespace of a ServingRuntime; empty for a ClusterServingRuntime
+	Namespace string `json:"namespace,omitempty"`
+
+	// Images are the container images of the runtime that were checked
+	Images []string `json:"images,omitempty"`
+
+	// ProbeModel is the storageUri served by the probe InferenceService, if any
+	ProbeModel string `json:"probeModel,omitempty"`
+
+	// Phase of the verification
+	// +kubebuilder:validation:Enum=PullingImages;ProbingInferenceService;Verified;Failed
+	Phase string `json:"phase"`
+
+	// Message explains a failed verification
+	Message string `json:"message,omitempty"`
+}
+
+// ApprovalStatus records the approval of a spec
+type ApprovalStatus struct {
+	// ApprovedBy is the approver named in the approve annotation
+	ApprovedBy string `json:"approvedBy"`
+
+	// ApprovedAt is when the operator saw the approval
+	ApprovedAt metav1.Time `json:"approvedAt,omitempty"`
+
+	// SpecHash is the hash of the approved spec
+	SpecHash string `json:"specHash"`
+}
+
+// VersionDiff is a resource-level comparison of two KServe release manifests
+type VersionDiff struct {
+	// FromVersion is the installed version
+	FromVersion string `json:"fromVersion"`
+
+	// ToVersion is the version requested for comparison
+	ToVersion string `json:"toVersion"`
+
+	// Added resources only exist in ToVersion
+	Added []ResourceRef `json:"added,omitempty"`
+
+	// Removed resources only exist in FromVersion
+	Removed []ResourceRef `json:"removed,omitempty"`
+
+	// Changed resources exist in both versions with different content
+	Changed []ResourceRef `json:"changed,omitempty"`
+
+	// Error explains why the diff could not be computed
+	Error string `json:"error,omitempty"`
+
+	// GeneratedAt is when the diff was computed
+	GeneratedAt metav1.Time `json:"generatedAt,omitempty"`
+}
+
+// ComponentStatus records the last successful apply of a component
+type ComponentStatus struct {
+	// Name of the component
+	Name string `json:"name"`
+
+	// Version of the component that was applied
+	Version string `json:"version,omitempty"`
+
+	// InstalledByOperatorVersion is the version of the operator build that applied it
+	InstalledByOperatorVersion string `json:"installedByOperatorVersion,omitempty"`
+
+	// LastAppliedTime is when applying the component last created or changed
+	// a resource. Reconciles that find everything in place leave it as is.
+	LastAppliedTime metav1.Time `json:"lastAppliedTime,omitempty"`
+}
+
+// InferenceServiceStatus records the deployment metadata of an InferenceService
+// applied by the operator
+type InferenceServiceStatus struct {
+	// Name of the InferenceService
+	Name string `json:"name"`
+
+	// Namespace of the InferenceService
+	Namespace string `json:"namespace,omitempty"`
+
+	// KServeVersion is the KServe version the InferenceService was applied with
+	KServeVersion string `json:"kserveVersion,omitempty"`
+
+	// AppliedAt is when the operator last wrote the InferenceService
+	AppliedAt metav1.Time `json:"appliedAt,omitempty"`
+}
+
+// UpgradeCheckpoint marks which components are already at TargetVersion
+type UpgradeCheckpoint struct {
+	// TargetVersion the checkpoint applies to; a different desired version invalidates it
+	TargetVersion string `json:"targetVersion"`
+
+	// CompletedComponents have been deployed at TargetVersion
+	CompletedComponents []string `json:"completedComponents,omitempty"`
+
+	// StartedAt is when the upgrade began
+	StartedAt metav1.Time `json:"startedAt,omitempty"`
+}
+
+// ManifestStatus records the resources applied from an extra manifest
+type ManifestStatus struct {
+	// Name of the manifest as declared in the spec
+	Name string `json:"name"`
+
+	// Resources applied from the manifest
+	Resources []ResourceRef `json:"resources,omitempty"`
+}
+
+// ResourceRef identifies a resource applied by the operator
+type ResourceRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// JobStatus defines the observed state of a post-install Job
+type JobStatus struct {
+	// Name of the Job as declared in the spec
+	Name string `json:"name"`
+
+	// Phase of the Job (Running, Succeeded, Failed)
+	// +kubebuilder:validation:Enum=Running;Succeeded;Failed
+	Phase string `json:"phase"`
+
+	// Message describing the outcome
+	Message string `json:"message,omitempty"`
+
+	// LogsRef points at the Job's logs
+	LogsRef string `json:"logsRef,omitempty"`
+
+	// CompletionTime of the Job
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Hash of the version and Job spec the outcome is for; the Job runs
+	// again when either changes
+	Hash string `json:"hash,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 // +kubebuilder:resource:shortName=ksd
 // +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.spec.version`
 // +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
@@ -0,0 +1,297 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the KServeDeployment validating webhook.
+// There is deliberately no defaulting webhook: defaults come from the CRD
+// schema so admission never rewrites a spec that GitOps tools manage.
+func (r *KServeDeployment) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		WithValidator(&kserveDeploymentValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-platform-ai-platform-io-v1alpha1-kservedeployment,mutating=false,failurePolicy=fail,sideEffects=None,groups=platform.ai-platform.io,resources=kservedeployments,verbs=create;update,versions=v1alpha1,name=vkservedeployment.platform.ai-platform.io,admissionReviewVersions=v1
+
+// kserveDeploymentValidator rejects invalid KServeDeployment specs at admission
+type kserveDeploymentValidator struct{}
+
+func (v *kserveDeploymentValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return validateObject(obj)
+}
+
+func (v *kserveDeploymentValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	return validateObject(newObj)
+}
+
+func (v *kserveDeploymentValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func validateObject(obj runtime.Object) (admission.Warnings, error) {
+	kd, ok := obj.(*KServeDeployment)
+	if !ok {
+		return nil, fmt.Errorf("expected a KServeDeployment but got a %T", obj)
+	}
+
+	if errs := kd.ValidateSpec(); len(errs) > 0 {
+		return nil, apierrors.NewInvalid(GroupVersion.WithKind("KServeDeployment").GroupKind(), kd.Name, errs)
+	}
+	return nil, nil
+}
+
+// ValidateSpec checks the spec for values that would produce a broken install
+func (r *KServeDeployment) ValidateSpec() field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	if r.Spec.Config != nil {
+		errs = append(errs, validateConfig(r.Spec.Config, specPath.Child("config"))...)
+	}
+
+	seen := map[string]bool{}
+	for i, component := range r.Spec.Components {
+		if seen[component] {
+			errs = append(errs, field.Duplicate(specPath.Child("components").Index(i), component))
+		}
+		seen[component] = true
+	}
+
+	errs = append(errs, metav1validation.ValidateLabels(r.Spec.NodeSelector, specPath.Child("nodeSelector"))...)
+	for i, toleration := range r.Spec.Tolerations {
+		errs = append(errs, validateToleration(toleration, specPath.Child("tolerations").Index(i))...)
+	}
+	if r.Spec.Affinity != nil {
+		errs = append(errs, validateAffinity(r.Spec.Affinity, specPath.Child("affinity"))...)
+	}
+	if r.Spec.PriorityClassName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(r.Spec.PriorityClassName) {
+			errs = append(errs, field.Invalid(specPath.Child("priorityClassName"), r.Spec.PriorityClassName, msg))
+		}
+	}
+	flags := make([]string, 0, len(r.Spec.FeatureFlags))
+	for name := range r.Spec.FeatureFlags {
+		flags = append(flags, name)
+	}
+	sort.Strings(flags)
+	for _, name := range flags {
+		if !knownFeatureFlag(name) {
+			errs = append(errs, field.NotSupported(specPath.Child("featureFlags").Key(name), name, KnownFeatureFlags))
+		}
+	}
+	for i, ref := range r.Spec.ExtraManifests {
+		if ref.ConfigMapRef != nil && ref.ConfigMapRef.Namespace != "" && ref.ConfigMapRef.Namespace != r.Namespace {
+			errs = append(errs, field.Invalid(specPath.Child("extraManifests").Index(i).Child("configMapRef", "namespace"),
+				ref.ConfigMapRef.Namespace, "must be the KServeDeployment's namespace"))
+		}
+	}
+	if r.Spec.DeletionGracePeriod != nil && r.Spec.DeletionGracePeriod.Duration < 0 {
+		errs = append(errs, field.Invalid(specPath.Child("deletionGracePeriod"), r.Spec.DeletionGracePeriod.Duration.String(), "must not be negative"))
+	}
+
+	return errs
+}
+
+func knownFeatureFlag(name string) bool {
+	for _, known := range KnownFeatureFlags {
+		if name == known {
+			return true
+		}
+	}
+	return false
+}
+
+func validateConfig(config *KServeConfig, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if config.IngressDomain != "" {
+		errs = append(errs, validateIngressDomain(config.IngressDomain, path.Child("ingressDomain"))...)
+	}
+
+	// Serverless mode needs a networking layer: Istio, or Kourier without a mesh
+	switch {
+	case config.NetworkingLayer != "" && !config.EnableKnative:
+		errs = append(errs, field.Invalid(path.Child("networkingLayer"), config.NetworkingLayer,
+			"networkingLayer only applies with enableKnative"))
+	case config.EnableKnative && config.NetworkingLayer != "Kourier" && !config.EnableIstio:
+		errs = append(errs, field.Invalid(path.Child("enableKnative"), config.EnableKnative,
+			"enableKnative requires enableIstio, or networkingLayer Kourier; use RawDeployment mode (both false) without a networking layer"))
+	}
+
+	return errs
+}
+
+// validateIngressDomain checks the domain is a DNS-1123 subdomain, calling
+// out the common mistakes explicitly before falling back to the generic rules
+func validateIngressDomain(domain string, path *field.Path) field.ErrorList {
+	switch {
+	case strings.Contains(domain, "://"):
+		return field.ErrorList{field.Invalid(path, domain, "must be a bare domain without a scheme (e.g. example.com, not https://example.com)")}
+	case strings.ContainsAny(domain, " \t"):
+		return field.ErrorList{field.Invalid(path, domain, "must not contain whitespace")}
+	case strings.ToLower(domain) != domain:
+		return field.ErrorList{field.Invalid(path, domain, "must be lowercase")}
+	}
+
+	var errs field.ErrorList
+	for _, msg := range validation.IsDNS1123Subdomain(domain) {
+		errs = append(errs, field.Invalid(path, domain, msg))
+	}
+	return errs
+}
+
+func validateToleration(toleration corev1.Toleration, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if toleration.Key != "" {
+		for _, msg := range validation.IsQualifiedName(toleration.Key) {
+			errs = append(errs, field.Invalid(path.Child("key"), toleration.Key, msg))
+		}
+	}
+
+	switch toleration.Operator {
+	case corev1.TolerationOpEqual, "":
+		if toleration.Key == "" {
+			errs = append(errs, field.Invalid(path.Child("operator"), toleration.Operator, "must be Exists when key is empty"))
+		}
+	case corev1.TolerationOpExists:
+		if toleration.Value != "" {
+			errs = append(errs, field.Invalid(path.Child("value"), toleration.Value, "must be empty when operator is Exists"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(path.Child("operator"), toleration.Operator,
+			[]string{string(corev1.TolerationOpEqual), string(corev1.TolerationOpExists)}))
+	}
+
+	switch toleration.Effect {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("effect"), toleration.Effect,
+			[]string{string(corev1.TaintEffectNoSchedule), string(corev1.TaintEffectPreferNoSchedule), string(corev1.TaintEffectNoExecute)}))
+	}
+
+	if toleration.TolerationSeconds != nil && toleration.Effect != corev1.TaintEffectNoExecute {
+		errs = append(errs, field.Invalid(path.Child("effect"), toleration.Effect, "must be NoExecute when tolerationSeconds is set"))
+	}
+
+	return errs
+}
+
+// validateAffinity checks the selectors of an affinity, which is all the
+// API server would otherwise only reject when the Deployment is applied
+func validateAffinity(affinity *corev1.Affinity, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if na := affinity.NodeAffinity; na != nil {
+		naPath := path.Child("nodeAffinity")
+		if required := na.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+			termsPath := naPath.Child("requiredDuringSchedulingIgnoredDuringExecution", "nodeSelectorTerms")
+			if len(required.NodeSelectorTerms) == 0 {
+				errs = append(errs, field.Required(termsPath, "must have at least one node selector term"))
+			}
+			for i, term := range required.NodeSelectorTerms {
+				errs = append(errs, validateNodeSelectorTerm(term, termsPath.Index(i))...)
+			}
+		}
+		for i, preferred := range na.PreferredDuringSchedulingIgnoredDuringExecution {
+			prefPath := naPath.Child("preferredDuringSchedulingIgnoredDuringExecution").Index(i)
+			if preferred.Weight < 1 || preferred.Weight > 100 {
+				errs = append(errs, field.Invalid(prefPath.Child("weight"), preferred.Weight, "must be in the range 1-100"))
+			}
+			errs = append(errs, validateNodeSelectorTerm(preferred.Preference, prefPath.Child("preference"))...)
+		}
+	}
+
+	if pa := affinity.PodAffinity; pa != nil {
+		errs = append(errs, validatePodAffinityTerms(pa.RequiredDuringSchedulingIgnoredDuringExecution, pa.PreferredDuringSchedulingIgnoredDuringExecution, path.Child("podAffinity"))...)
+	}
+	if paa := affinity.PodAntiAffinity; paa != nil {
+		errs = append(errs, validatePodAffinityTerms(paa.RequiredDuringSchedulingIgnoredDuringExecution, paa.PreferredDuringSchedulingIgnoredDuringExecution, path.Child("podAntiAffinity"))...)
+	}
+
+	return errs
+}
+
+func validateNodeSelectorTerm(term corev1.NodeSelectorTerm, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	for i, req := range term.MatchExpressions {
+		reqPath := path.Child("matchExpressions").Index(i)
+		for _, msg := range validation.IsQualifiedName(req.Key) {
+			errs = append(errs, field.Invalid(reqPath.Child("key"), req.Key, msg))
+		}
+
+		switch req.Operator {
+		case corev1.NodeSelectorOpIn, corev1.NodeSelectorOpNotIn:
+			if len(req.Values) == 0 {
+				errs = append(errs, field.Required(reqPath.Child("values"), "must be specified for operator In or NotIn"))
+			}
+		case corev1.NodeSelectorOpExists, corev1.NodeSelectorOpDoesNotExist:
+			if len(req.Values) > 0 {
+				errs = append(errs, field.Forbidden(reqPath.Child("values"), "may not be specified for operator Exists or DoesNotExist"))
+			}
+		case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+			if len(req.Values) != 1 {
+				errs = append(errs, field.Required(reqPath.Child("values"), "must have exactly one value for operator Gt or Lt"))
+			}
+		default:
+			errs = append(errs, field.NotSupported(reqPath.Child("operator"), req.Operator, []string{
+				string(corev1.NodeSelectorOpIn), string(corev1.NodeSelectorOpNotIn),
+				string(corev1.NodeSelectorOpExists), string(corev1.NodeSelectorOpDoesNotExist),
+				string(corev1.NodeSelectorOpGt), string(corev1.NodeSelectorOpLt),
+			}))
+		}
+	}
+
+	return errs
+}
+
+func validatePodAffinityTerms(required []corev1.PodAffinityTerm, preferred []corev1.WeightedPodAffinityTerm, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	for i, term := range required {
+		errs = append(errs, validatePodAffinityTerm(term, path.Child("requiredDuringSchedulingIgnoredDuringExecution").Index(i))...)
+	}
+	for i, weighted := range preferred {
+		prefPath := path.Child("preferredDuringSchedulingIgnoredDuringExecution").Index(i)
+		if weighted.Weight < 1 || weighted.Weight > 100 {
+			errs = append(errs, field.Invalid(prefPath.Child("weight"), weighted.Weight, "must be in the range 1-100"))
+		}
+		errs = append(errs, validatePodAffinityTerm(weighted.PodAffinityTerm, prefPath.Child("podAffinityTerm"))...)
+	}
+
+	return errs
+}
+
+func validatePodAffinityTerm(term corev1.PodAffinityTerm, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+
+	if term.TopologyKey == "" {
+		errs = append(errs, field.Required(path.Child("topologyKey"), "must be specified"))
+	} else {
+		for _, msg := range validation.IsQualifiedName(term.TopologyKey) {
+			errs = append(errs, field.Invalid(path.Child("topologyKey"), term.TopologyKey, msg))
+		}
+	}
+
+	opts := metav1validation.LabelSelectorValidationOptions{}
+	errs = append(errs, metav1validation.ValidateLabelSelector(term.LabelSelector, opts, path.Child("labelSelector"))...)
+	errs = append(errs, metav1validation.ValidateLabelSelector(term.NamespaceSelector, opts, path.Child("namespaceSelector"))...)
+
+	return errs
+}
@@ -0,0 +1,173 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// networkingAnnotation keeps the v1beta1 networking block on the stored
+// v1alpha1 object. v1alpha1 can only express it as two flags, so without it
+// values such as an unset deploymentMode would not survive a round trip.
+const networkingAnnotation = "platform.ai-platform.io/v1beta1-networking"
+
+// ConvertTo converts this KServeDeployment to the v1alpha1 hub
+func (src *KServeDeployment) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v1alpha1.KServeDeployment)
+	if !ok {
+		return fmt.Errorf("expected a v1alpha1 KServeDeployment but got a %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = v1alpha1.KServeDeploymentSpec{
+		Version:                   src.Spec.Version,
+		Components:                src.Spec.Components,
+		Namespace:                 src.Spec.Namespace,
+		ComponentNamespaces:       src.Spec.ComponentNamespaces,
+		Config:                    configFromNetworking(src.Spec.Networking),
+		PostInstallJobs:           src.Spec.PostInstallJobs,
+		ExtraManifests:            src.Spec.ExtraManifests,
+		ApplyStrategy:             src.Spec.ApplyStrategy,
+		ForceOwnership:            src.Spec.ForceOwnership,
+		InferenceService:          src.Spec.InferenceService,
+		ImagePullSecrets:          src.Spec.ImagePullSecrets,
+		NodeSelector:              src.Spec.NodeSelector,
+		Tolerations:               src.Spec.Tolerations,
+		Affinity:                  src.Spec.Affinity,
+		DefaultRuntime:            src.Spec.DefaultRuntime,
+		DeletionGracePeriod:       src.Spec.DeletionGracePeriod,
+		AdoptExisting:             src.Spec.AdoptExisting,
+		FeatureFlags:              src.Spec.FeatureFlags,
+		AllowDowngrade:            src.Spec.AllowDowngrade,
+		ReconcileSchedule:         src.Spec.ReconcileSchedule,
+		AtomicInstall:             src.Spec.AtomicInstall,
+		PriorityClassName:         src.Spec.PriorityClassName,
+		RequireApproval:           src.Spec.RequireApproval,
+		RuntimeVerification:       src.Spec.RuntimeVerification,
+		InvalidSpecPolicy:         src.Spec.InvalidSpecPolicy,
+		ManifestValidation:        src.Spec.ManifestValidation,
+		InferenceServiceReadiness: src.Spec.InferenceServiceReadiness,
+		DeleteNamespaceOnCleanup:  src.Spec.DeleteNamespaceOnCleanup,
+		DeleteCRDsOnCleanup:       src.Spec.DeleteCRDsOnCleanup,
+		PinManifests:              src.Spec.PinManifests,
+		StorageCredentials:        src.Spec.StorageCredentials,
+	}
+	dst.Status = src.Status
+
+	delete(dst.Annotations, networkingAnnotation)
+	if src.Spec.Networking != nil {
+		networking, err := json.Marshal(src.Spec.Networking)
+		if err != nil {
+			return fmt.Errorf("failed to preserve networking: %w", err)
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[networkingAnnotation] = string(networking)
+	}
+	return nil
+}
+
+// ConvertFrom converts from the v1alpha1 hub to this version
+func (dst *KServeDeployment) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v1alpha1.KServeDeployment)
+	if !ok {
+		return fmt.Errorf("expected a v1alpha1 KServeDeployment but got a %T", srcRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	dst.Spec = KServeDeploymentSpec{
+		Version:                   src.Spec.Version,
+		Components:                src.Spec.Components,
+		Namespace:                 src.Spec.Namespace,
+		ComponentNamespaces:       src.Spec.ComponentNamespaces,
+		Networking:                networkingFromConfig(src.Spec.Config),
+		PostInstallJobs:           src.Spec.PostInstallJobs,
+		ExtraManifests:            src.Spec.ExtraManifests,
+		ApplyStrategy:             src.Spec.ApplyStrategy,
+		ForceOwnership:            src.Spec.ForceOwnership,
+		InferenceService:          src.Spec.InferenceService,
+		ImagePullSecrets:          src.Spec.ImagePullSecrets,
+		NodeSelector:              src.Spec.NodeSelector,
+		Tolerations:               src.Spec.Tolerations,
+		Affinity:                  src.Spec.Affinity,
+		DefaultRuntime:            src.Spec.DefaultRuntime,
+		DeletionGracePeriod:       src.Spec.DeletionGracePeriod,
+		AdoptExisting:             src.Spec.AdoptExisting,
+		FeatureFlags:              src.Spec.FeatureFlags,
+		AllowDowngrade:            src.Spec.AllowDowngrade,
+		ReconcileSchedule:         src.Spec.ReconcileSchedule,
+		AtomicInstall:             src.Spec.AtomicInstall,
+		PriorityClassName:         src.Spec.PriorityClassName,
+		RequireApproval:           src.Spec.RequireApproval,
+		RuntimeVerification:       src.Spec.RuntimeVerification,
+		InvalidSpecPolicy:         src.Spec.InvalidSpecPolicy,
+		ManifestValidation:        src.Spec.ManifestValidation,
+		InferenceServiceReadiness: src.Spec.InferenceServiceReadiness,
+		DeleteNamespaceOnCleanup:  src.Spec.DeleteNamespaceOnCleanup,
+		DeleteCRDsOnCleanup:       src.Spec.DeleteCRDsOnCleanup,
+		PinManifests:              src.Spec.PinManifests,
+		StorageCredentials:        src.Spec.StorageCredentials,
+	}
+	dst.Status = src.Status
+
+	// Prefer the preserved networking block unless the object was changed
+	// through v1alpha1 since it was written
+	if preserved, ok := dst.Annotations[networkingAnnotation]; ok {
+		delete(dst.Annotations, networkingAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+
+		networking := &NetworkingSpec{}
+		if err := json.Unmarshal([]byte(preserved), networking); err == nil &&
+			equivalentConfig(configFromNetworking(networking), src.Spec.Config) {
+			dst.Spec.Networking = networking
+		}
+	}
+	return nil
+}
+
+// configFromNetworking maps the v1beta1 networking block onto v1alpha1's flags
+func configFromNetworking(networking *NetworkingSpec) *v1alpha1.KServeConfig {
+	if networking == nil {
+		return nil
+	}
+	return &v1alpha1.KServeConfig{
+		IngressDomain:   networking.IngressDomain,
+		EnableKnative:   networking.DeploymentMode == "Serverless",
+		EnableIstio:     networking.ServiceMesh == "Istio",
+		NetworkingLayer: networking.NetworkingLayer,
+	}
+}
+
+// networkingFromConfig maps v1alpha1's flags onto the v1beta1 networking block
+func networkingFromConfig(config *v1alpha1.KServeConfig) *NetworkingSpec {
+	if config == nil {
+		return nil
+	}
+
+	networking := &NetworkingSpec{
+		IngressDomain:   config.IngressDomain,
+		DeploymentMode:  "RawDeployment",
+		ServiceMesh:     "None",
+		NetworkingLayer: config.NetworkingLayer,
+	}
+	if config.EnableKnative {
+		networking.DeploymentMode = "Serverless"
+	}
+	if config.EnableIstio {
+		networking.ServiceMesh = "Istio"
+	}
+	return networking
+}
+
+func equivalentConfig(a, b *v1alpha1.KServeConfig) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
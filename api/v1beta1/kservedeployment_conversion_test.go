@@ -0,0 +1,124 @@
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+func sampleSpec() KServeDeploymentSpec {
+	return KServeDeploymentSpec{
+		Version:             "v0.11.0",
+		Components:          []string{"kserve", "knative"},
+		Namespace:           "kserve",
+		ComponentNamespaces: map[string]string{"knative": "serving"},
+		ExtraManifests:      []v1alpha1.ManifestRef{{Name: "runtime", URL: "https://example.com/runtime.yaml"}},
+		ApplyStrategy:       "ServerSideApply",
+		ImagePullSecrets:    []string{"registry"},
+		NodeSelector:        map[string]string{"pool": "inference"},
+		DefaultRuntime:      "kserve-sklearnserver",
+		DeletionGracePeriod: &metav1.Duration{Duration: time.Minute},
+		FeatureFlags:        map[string]bool{"modelmesh": true},
+		AtomicInstall:       true,
+		PinManifests:        true,
+		StorageCredentials:  &v1alpha1.StorageCredentialsSpec{SecretName: "s3", AllowedNamespaces: []string{"models"}},
+	}
+}
+
+func TestV1beta1RoundTripsThroughTheHub(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		networking *NetworkingSpec
+	}{
+		{name: "no networking"},
+		{name: "defaults", networking: &NetworkingSpec{DeploymentMode: "RawDeployment", ServiceMesh: "None"}},
+		{name: "unset fields", networking: &NetworkingSpec{IngressDomain: "example.com"}},
+		{name: "serverless with kourier", networking: &NetworkingSpec{
+			IngressDomain: "example.com", DeploymentMode: "Serverless", ServiceMesh: "None", NetworkingLayer: "Kourier",
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			original := &KServeDeployment{ObjectMeta: metav1.ObjectMeta{Name: "kserve", Namespace: "platform"}}
+			original.Spec = sampleSpec()
+			original.Spec.Networking = tc.networking
+			original.Status.Phase = "Ready"
+
+			hub := &v1alpha1.KServeDeployment{}
+			if err := original.DeepCopy().ConvertTo(hub); err != nil {
+				t.Fatal(err)
+			}
+			converted := &KServeDeployment{}
+			if err := converted.ConvertFrom(hub); err != nil {
+				t.Fatal(err)
+			}
+
+			if !equality.Semantic.DeepEqual(original, converted) {
+				t.Errorf("round trip changed the object:\nbefore: %+v\nafter:  %+v", original, converted)
+			}
+		})
+	}
+}
+
+func TestV1alpha1RoundTripsThroughV1beta1(t *testing.T) {
+	original := &v1alpha1.KServeDeployment{ObjectMeta: metav1.ObjectMeta{Name: "kserve", Namespace: "platform"}}
+	original.Spec = v1alpha1.KServeDeploymentSpec{
+		Version: "v0.11.0",
+		Config: &v1alpha1.KServeConfig{
+			IngressDomain:   "example.com",
+			EnableIstio:     true,
+			EnableKnative:   true,
+			NetworkingLayer: "Istio",
+		},
+	}
+
+	spoke := &KServeDeployment{}
+	if err := spoke.ConvertFrom(original.DeepCopy()); err != nil {
+		t.Fatal(err)
+	}
+	if got := spoke.Spec.Networking; got == nil || got.DeploymentMode != "Serverless" || got.ServiceMesh != "Istio" {
+		t.Fatalf("config was not mapped onto networking: %+v", got)
+	}
+
+	converted := &v1alpha1.KServeDeployment{}
+	if err := spoke.ConvertTo(converted); err != nil {
+		t.Fatal(err)
+	}
+	// The preserved networking block describes the same config, so the
+	// hub object only differs by that annotation
+	delete(converted.Annotations, networkingAnnotation)
+	if len(converted.Annotations) == 0 {
+		converted.Annotations = nil
+	}
+	if !equality.Semantic.DeepEqual(original, converted) {
+		t.Errorf("round trip changed the object:\nbefore: %+v\nafter:  %+v", original, converted)
+	}
+}
+
+func TestPreservedNetworkingIsDroppedAfterAV1alpha1Edit(t *testing.T) {
+	original := &KServeDeployment{ObjectMeta: metav1.ObjectMeta{Name: "kserve", Namespace: "platform"}}
+	original.Spec.Version = "v0.11.0"
+	original.Spec.Networking = &NetworkingSpec{IngressDomain: "example.com"}
+
+	hub := &v1alpha1.KServeDeployment{}
+	if err := original.ConvertTo(hub); err != nil {
+		t.Fatal(err)
+	}
+	hub.Spec.Config.EnableKnative = true
+	hub.Spec.Config.EnableIstio = true
+
+	converted := &KServeDeployment{}
+	if err := converted.ConvertFrom(hub); err != nil {
+		t.Fatal(err)
+	}
+	want := &NetworkingSpec{IngressDomain: "example.com", DeploymentMode: "Serverless", ServiceMesh: "Istio"}
+	if !equality.Semantic.DeepEqual(converted.Spec.Networking, want) {
+		t.Errorf("networking = %+v, want %+v", converted.Spec.Networking, want)
+	}
+	if _, ok := converted.Annotations[networkingAnnotation]; ok {
+		t.Errorf("the %s annotation leaked into the v1beta1 object", networkingAnnotation)
+	}
+}
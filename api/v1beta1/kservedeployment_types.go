@@ -0,0 +1,211 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// KServeDeploymentSpec defines the desired state of KServe deployment. It
+// matches v1alpha1 except that the flat Config is replaced by Networking.
+type KServeDeploymentSpec struct {
+	// Version of KServe to deploy
+	Version string `json:"version"`
+
+	// Components to deploy (kserve, knative, istio, cert-manager)
+	Components []string `json:"components,omitempty"`
+
+	// Namespace where KServe will be installed
+	// +kubebuilder:default=kserve
+	Namespace string `json:"namespace,omitempty"`
+
+	// ComponentNamespaces overrides the namespace per component. Defaults are
+	// cert-manager -> cert-manager, knative -> knative-serving,
+	// istio -> istio-system and kserve -> Namespace.
+	ComponentNamespaces map[string]string `json:"componentNamespaces,omitempty"`
+
+	// Networking configures how inference services are exposed
+	Networking *NetworkingSpec `json:"networking,omitempty"`
+
+	// PostInstallJobs run once the core install is Ready (migrations, warmups, smoke tests)
+	PostInstallJobs []v1alpha1.JobSpec `json:"postInstallJobs,omitempty"`
+
+	// ExtraManifests are applied after the core components (Gateways, ServingRuntimes, dashboards)
+	ExtraManifests []v1alpha1.ManifestRef `json:"extraManifests,omitempty"`
+
+	// ApplyStrategy used for manifests (Update, ServerSideApply)
+	// +kubebuilder:validation:Enum=Update;ServerSideApply
+	// +kubebuilder:default=Update
+	ApplyStrategy string `json:"applyStrategy,omitempty"`
+
+	// ForceOwnership takes over fields owned by other field managers instead of
+	// reporting a FieldConflict condition. Only used with ServerSideApply.
+	ForceOwnership bool `json:"forceOwnership,omitempty"`
+
+	// InferenceService configures the InferenceService template deployed with KServe
+	InferenceService *v1alpha1.InferenceServiceTemplate `json:"inferenceService,omitempty"`
+
+	// ImagePullSecrets are added to the ServiceAccounts and pod specs of the
+	// applied workloads. Secrets missing from a component namespace are copied
+	// from the KServeDeployment's namespace.
+	ImagePullSecrets []string `json:"imagePullSecrets,omitempty"`
+
+	// NodeSelector is merged into the pod spec of the component Deployments
+	// (e.g. the KServe controller and webhook) to pin them to a node pool
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are added to the pod spec of the component Deployments
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity replaces the affinity of the component Deployments
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// DefaultRuntime is set as the runtime of applied InferenceServices whose
+	// model doesn't name one. It must be an installed ServingRuntime in the
+	// InferenceService's namespace or a ClusterServingRuntime.
+	DefaultRuntime string `json:"defaultRuntime,omitempty"`
+
+	// DeletionGracePeriod delays tearing down the managed resources after the
+	// KServeDeployment is deleted, so in-flight inference traffic can drain.
+	// The deployment reports Terminating in the meantime.
+	DeletionGracePeriod *metav1.Duration `json:"deletionGracePeriod,omitempty"`
+
+	// AdoptExisting takes over a KServe install that wasn't made by the
+	// operator instead of refusing to install over it
+	AdoptExisting bool `json:"adoptExisting,omitempty"`
+
+	// FeatureFlags toggle KServe features by name (see KnownFeatureFlags).
+	// They are written into KServe's inferenceservice-config ConfigMap on top
+	// of its base configuration.
+	FeatureFlags map[string]bool `json:"featureFlags,omitempty"`
+
+	// AllowDowngrade permits a Version older than the installed one
+	AllowDowngrade bool `json:"allowDowngrade,omitempty"`
+
+	// ReconcileSchedule is a cron expression (UTC, e.g. "0 3 * * *" or
+	// "@daily") at which every resource is re-applied, even unchanged ones,
+	// independent of the resync period
+	ReconcileSchedule string `json:"reconcileSchedule,omitempty"`
+
+	// AtomicInstall makes each component all-or-nothing: if any of its
+	// resources fails to apply, the resources it created are deleted again
+	AtomicInstall bool `json:"atomicInstall,omitempty"`
+
+	// PriorityClassName is set on the pods of the control-plane workloads
+	// (controllers and webhooks) so they aren't preempted under pressure. The
+	// PriorityClass must exist.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// RequireApproval holds every new spec in the Pending phase until it is
+	// approved with the platform.ai-platform.io/approve annotation
+	RequireApproval bool `json:"requireApproval,omitempty"`
+
+	// RuntimeVerification checks that the ServingRuntimes the operator
+	// applies are usable, not just applied. Unset disables it.
+	RuntimeVerification *v1alpha1.RuntimeVerificationSpec `json:"runtimeVerification,omitempty"`
+
+	// InvalidSpecPolicy decides what happens to the running install when the
+	// spec fails validation: Retain keeps the last good install untouched,
+	// TearDown deletes it. Either way the SpecInvalid condition is set.
+	// +kubebuilder:validation:Enum=Retain;TearDown
+	// +kubebuilder:default=Retain
+	InvalidSpecPolicy string `json:"invalidSpecPolicy,omitempty"`
+
+	// ManifestValidation checks the manifests against the cluster's OpenAPI
+	// schema before anything is applied. Warn reports unknown fields and type
+	// mismatches in the ManifestSchemaMismatch condition; Error also fails
+	// the reconcile.
+	// +kubebuilder:validation:Enum=Disabled;Warn;Error
+	// +kubebuilder:default=Disabled
+	ManifestValidation string `json:"manifestValidation,omitempty"`
+
+	// InferenceServiceReadiness decides whether the managed InferenceServices
+	// gate the Ready phase. Strict reports Ready only once all of them are
+	// Ready; BestEffort only reports them in the InferenceServicesReady
+	// condition.
+	// +kubebuilder:validation:Enum=Strict;BestEffort
+	// +kubebuilder:default=Strict
+	InferenceServiceReadiness string `json:"inferenceServiceReadiness,omitempty"`
+
+	// DeleteNamespaceOnCleanup deletes the namespaces the operator created
+	// (status.createdNamespaces) when the KServeDeployment is deleted. A
+	// namespace is kept if another KServeDeployment uses it or it contains
+	// resources the operator doesn't manage; pre-existing namespaces are
+	// never deleted.
+	DeleteNamespaceOnCleanup bool `json:"deleteNamespaceOnCleanup,omitempty"`
+
+	// DeleteCRDsOnCleanup lets the operator delete the CustomResourceDefinitions
+	// it applied, which deletes every custom resource of their kinds, when the
+	// KServeDeployment is deleted, torn down, rolled back or pruned. They are
+	// left in place by default.
+	DeleteCRDsOnCleanup bool `json:"deleteCRDsOnCleanup,omitempty"`
+
+	// PinManifests snapshots the rendered manifests into a ConfigMap once the
+	// install is first Ready and from then on applies the snapshot instead of
+	// fetching the manifest sources. A version bump or the
+	// platform.ai-platform.io/resnapshot annotation reconciles from the
+	// sources again and replaces the snapshot. Other spec changes that alter
+	// the manifests only take effect with a new snapshot.
+	PinManifests bool `json:"pinManifests,omitempty"`
+
+	// StorageCredentials gives the storage initializer of applied
+	// InferenceServices access to the model storage. The operator copies the
+	// referenced Secret into each InferenceService namespace with KServe's
+	// storage annotations and creates a ServiceAccount that references it.
+	StorageCredentials *v1alpha1.StorageCredentialsSpec `json:"storageCredentials,omitempty"`
+}
+
+// NetworkingSpec groups the networking options that v1alpha1 kept as flags
+// on KServeConfig
+type NetworkingSpec struct {
+	// IngressDomain for KServe endpoints
+	IngressDomain string `json:"ingressDomain,omitempty"`
+
+	// DeploymentMode of inference services (RawDeployment, Serverless).
+	// Serverless replaces v1alpha1's enableKnative.
+	// +kubebuilder:validation:Enum=RawDeployment;Serverless
+	// +kubebuilder:default=RawDeployment
+	DeploymentMode string `json:"deploymentMode,omitempty"`
+
+	// ServiceMesh integrated with KServe (None, Istio). Istio replaces
+	// v1alpha1's enableIstio.
+	// +kubebuilder:validation:Enum=None;Istio
+	// +kubebuilder:default=None
+	ServiceMesh string `json:"serviceMesh,omitempty"`
+
+	// NetworkingLayer Knative routes Serverless traffic through (Istio,
+	// Kourier). Empty means Istio.
+	// +kubebuilder:validation:Enum=Istio;Kourier
+	NetworkingLayer string `json:"networkingLayer,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=ksd
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.spec.version`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// KServeDeployment is the Schema for deploying KServe
+type KServeDeployment struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   KServeDeploymentSpec            `json:"spec,omitempty"`
+	Status v1alpha1.KServeDeploymentStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// KServeDeploymentList contains a list of KServeDeployment
+type KServeDeploymentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []KServeDeployment `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&KServeDeployment{}, &KServeDeploymentList{})
+}
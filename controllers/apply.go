@@ -0,0 +1,567 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// contentHashAnnotation records a hash of the desired state of each applied
+// resource so unchanged resources can skip the Update on later reconciles
+const contentHashAnnotation = "platform.ai-platform.io/content-hash"
+
+// keepExistingAnnotation marks a rendered ConfigMap its source leaves alone
+// once it exists, so the rendered manifests can be applied as the source was.
+// It is removed before the ConfigMap is applied.
+const keepExistingAnnotation = "platform.ai-platform.io/keep-existing"
+
+// applyOptions tune how decoded manifests are applied
+type applyOptions struct {
+	// namespace overrides the declared namespace of namespaced resources
+	namespace string
+
+	// defaultNamespace is given to namespaced resources that don't declare a
+	// namespace, so they don't land in "default"
+	defaultNamespace string
+
+	// skipExistingConfigMaps leaves ConfigMaps that already exist untouched
+	skipExistingConfigMaps bool
+
+	// serverSideApply applies resources with server-side apply instead of Create/Update
+	serverSideApply bool
+
+	// forceOwnership takes over fields owned by other field managers (server-side apply only)
+	forceOwnership bool
+
+	// trackCreated records the resources a server-side apply creates so they
+	// can be rolled back (Create/Update tells them apart for free)
+	trackCreated bool
+
+	// keepApplied records each applied object as it was sent, so the
+	// manifest snapshot holds exactly what was installed
+	keepApplied bool
+
+	// mutators adjust each decoded object before it is applied
+	mutators []objectMutator
+}
+
+// objectMutator patches a decoded object before it is applied
+type objectMutator func(obj *unstructured.Unstructured) error
+
+// fieldManager is the field manager name used for server-side apply
+const fieldManager = "ai-platform-operator"
+
+// applyOptionsFor returns the apply options requested by the spec
+func applyOptionsFor(kd *platformv1alpha1.KServeDeployment) applyOptions {
+	opts := applyOptions{
+		defaultNamespace: kd.Spec.Namespace,
+		serverSideApply:  kd.Spec.ApplyStrategy == "ServerSideApply",
+		forceOwnership:   kd.Spec.ForceOwnership,
+		trackCreated:     kd.Spec.AtomicInstall,
+		keepApplied:      kd.Spec.PinManifests && !manifestSnapshotPinned(kd),
+	}
+
+	if len(kd.Spec.ImagePullSecrets) > 0 {
+		opts.mutators = append(opts.mutators, injectImagePullSecrets(kd.Spec.ImagePullSecrets))
+	}
+	if kd.Spec.DefaultRuntime != "" {
+		opts.mutators = append(opts.mutators, setDefaultRuntime(kd.Spec.DefaultRuntime))
+	}
+	if kd.Spec.StorageCredentials != nil {
+		opts.mutators = append(opts.mutators, useStorageServiceAccount(storageServiceAccountName(kd)))
+	}
+
+	return opts
+}
+
+// fetchManifest downloads the manifest at url, serving it from the manifest
+// cache when possible and short-circuiting while the source's circuit breaker
+// is open. A non-empty checksum is verified against the downloaded bytes.
+func (r *KServeDeploymentReconciler) fetchManifest(ctx context.Context, url, checksum string) (manifestBytes []byte, err error) {
+	ctx, span := startSpan(ctx, "FetchManifest", attribute.String("manifest.url", url))
+	defer func() {
+		span.SetAttributes(attribute.Int("manifest.bytes", len(manifestBytes)))
+		endSpan(span, err)
+	}()
+
+	if cached, ok := r.ManifestCache.Get(url, checksum); ok {
+		log.FromContext(ctx).V(1).Info("Using cached manifest", "url", url)
+		span.SetAttributes(attribute.Bool("manifest.cached", true))
+		return cached, nil
+	}
+
+	if err := r.SourceBreaker.Allow(url); err != nil {
+		return nil, err
+	}
+
+	fetchStart := time.Now()
+	manifestBytes, err = r.downloadManifest(ctx, url)
+	if shuttingDown(ctx) {
+		// An aborted fetch says nothing about the health of the source
+		return nil, ctx.Err()
+	}
+	r.SourceBreaker.Record(url, err)
+	if err != nil {
+		return nil, withReason(platformv1alpha1.ReasonManifestFetchFailed, err)
+	}
+	r.observeFetchLatency(ctx, url, time.Since(fetchStart))
+
+	if err := verifyChecksum(manifestBytes, checksum); err != nil {
+		return nil, err
+	}
+
+	if err := r.ManifestCache.Put(url, checksum, manifestBytes); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to cache manifest", "url", url)
+	}
+	return manifestBytes, nil
+}
+
+func (r *KServeDeploymentReconciler) downloadManifest(ctx context.Context, url string) ([]byte, error) {
+	logger := log.FromContext(ctx)
+
+	logger.Info("Fetching manifest", "url", url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch manifest: status %d", resp.StatusCode)
+	}
+
+	// Read the entire response
+	manifestBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	return manifestBytes, nil
+}
+
+// decodeManifests splits a multi-document YAML (or JSON) manifest into objects,
+// skipping empty and undecodable documents
+func decodeManifests(ctx context.Context, manifestBytes []byte) []unstructured.Unstructured {
+	logger := log.FromContext(ctx)
+
+	_, span := startSpan(ctx, "DecodeManifests", attribute.Int("manifest.bytes", len(manifestBytes)))
+	defer span.End()
+
+	objs := []unstructured.Unstructured{}
+	skipped := 0
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifestBytes), 4096)
+	for {
+		var obj unstructured.Unstructured
+		if err := decoder.Decode(&obj); err != nil {
+			if err == io.EOF {
+				break
+			}
+			logger.Info("Skipping invalid YAML document", "error", err)
+			skipped++
+			continue
+		}
+
+		if obj.Object == nil {
+			continue
+		}
+
+		objs = append(objs, obj)
+	}
+
+	span.SetAttributes(attribute.Int("resources.decoded", len(objs)), attribute.Int("resources.skipped", skipped))
+	return objs
+}
+
+// applyManifests decodes and applies every document in manifestBytes, CRDs
+// first, returning references to the resources that were applied. Failures
+// on individual resources are logged and the rest still applied.
+func (r *KServeDeploymentReconciler) applyManifests(ctx context.Context, manifestBytes []byte, opts applyOptions) (applied []platformv1alpha1.ResourceRef, err error) {
+	logger := log.FromContext(ctx)
+
+	failed := 0
+	ctx, span := startSpan(ctx, "ApplyManifests")
+	defer func() {
+		span.SetAttributes(attribute.Int("resources.applied", len(applied)), attribute.Int("resources.failed", failed))
+		endSpan(span, err)
+	}()
+
+	applied = []platformv1alpha1.ResourceRef{}
+	crds := []string{}
+	for _, obj := range crdsFirst(decodeManifests(ctx, manifestBytes)) {
+		// Stop between resources when the operator is shutting down
+		if shuttingDown(ctx) {
+			return applied, ctx.Err()
+		}
+
+		obj := obj
+
+		// Custom resources in the same manifest need their CRDs served first
+		if len(crds) > 0 && !isCRD(&obj) {
+			r.establishCRDs(ctx, crds)
+			crds = nil
+		}
+
+		if opts.namespace != "" && obj.GetNamespace() != "" {
+			obj.SetNamespace(opts.namespace)
+		}
+		if opts.defaultNamespace != "" && obj.GetNamespace() == "" {
+			r.setDefaultNamespace(ctx, &obj, opts.defaultNamespace)
+		}
+		setLabel(&obj, managedByLabel, managedByValue)
+
+		// A snapshotted ConfigMap keeps the skip of the source it came from
+		objOpts := opts
+		if takeKeepExisting(&obj) {
+			objOpts.skipExistingConfigMaps = true
+		}
+
+		if err := mutateObject(&obj, opts.mutators); err != nil {
+			logger.Error(err, "Failed to patch resource", "kind", obj.GetKind(), "name", obj.GetName())
+			recordApplyFailure(ctx, resourceRefFor(&obj))
+			failed++
+			continue
+		}
+
+		// An object outside the watch namespace stops the rest of the manifest
+		if err := r.checkObjectScope(&obj); err != nil {
+			return applied, withReason(platformv1alpha1.ReasonScopeConflict, err)
+		}
+
+		// Rendering for review stops short of the API server
+		if state := reconcileStateFrom(ctx); state != nil && state.render {
+			if err := stampRendered(&obj, objOpts.skipExistingConfigMaps); err != nil {
+				return applied, err
+			}
+			state.rendered = append(state.rendered, obj)
+			continue
+		}
+
+		ref := resourceRefFor(&obj)
+		if err := r.applyObjectWithRetry(ctx, &obj, objOpts); err != nil {
+			logger.Error(err, "Failed to apply resource", "kind", obj.GetKind(), "name", obj.GetName())
+			recordApplyFailure(ctx, ref)
+			failed++
+			continue
+		}
+		applied = append(applied, ref)
+		recordApplied(ctx, ref)
+		// The retry applies copies, so obj is still what was sent
+		if opts.keepApplied {
+			recordAppliedObject(ctx, &obj, objOpts.skipExistingConfigMaps)
+		}
+		if isCRD(&obj) {
+			crds = append(crds, obj.GetName())
+		}
+	}
+
+	return applied, nil
+}
+
+// mutateObject runs each mutator over obj in order
+func mutateObject(obj *unstructured.Unstructured, mutators []objectMutator) error {
+	for _, mutate := range mutators {
+		if err := mutate(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyObject creates obj, or updates the live object when its content hash
+// differs from the desired one
+func (r *KServeDeploymentReconciler) applyObject(ctx context.Context, obj *unstructured.Unstructured, opts applyOptions) error {
+	logger := log.FromContext(ctx)
+
+	hash, err := stampContentHash(obj)
+	if err != nil {
+		return err
+	}
+
+	logger.V(1).Info("Applying resource",
+		"kind", obj.GetKind(),
+		"name", obj.GetName(),
+		"namespace", obj.GetNamespace())
+
+	if opts.serverSideApply {
+		return r.serverSideApply(ctx, obj, opts)
+	}
+
+	// Try to create the resource
+	err = r.Create(ctx, obj)
+	if err == nil {
+		logger.Info("Created resource", "kind", obj.GetKind(), "name", obj.GetName(), "namespace", obj.GetNamespace())
+		recordCreated(ctx, resourceRefFor(obj))
+		recordChanged(ctx)
+		return nil
+	}
+	if !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	if opts.skipExistingConfigMaps && obj.GetKind() == "ConfigMap" {
+		logger.Info("ConfigMap already exists, skipping update", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		return nil
+	}
+
+	// Get the existing resource
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	if err := r.Get(ctx, client.ObjectKeyFromObject(obj), existing); err != nil {
+		return fmt.Errorf("failed to get existing resource: %w", err)
+	}
+
+	state := reconcileStateFrom(ctx)
+	forceApply := state != nil && state.forceApply
+	if existing.GetAnnotations()[contentHashAnnotation] == hash && !forceApply {
+		logger.V(1).Info("Resource unchanged, skipping update", "kind", obj.GetKind(), "name", obj.GetName())
+		return nil
+	}
+
+	logger.Info("Resource already exists, updating", "kind", obj.GetKind(), "name", obj.GetName())
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	if err := r.Update(ctx, obj); err != nil {
+		return fmt.Errorf("failed to update resource: %w", err)
+	}
+	recordChanged(ctx)
+	return nil
+}
+
+// serverSideApply applies obj with server-side apply. Field manager conflicts
+// are recorded for the FieldConflict condition rather than forced, unless
+// ownership forcing was requested.
+func (r *KServeDeploymentReconciler) serverSideApply(ctx context.Context, obj *unstructured.Unstructured, opts applyOptions) error {
+	logger := log.FromContext(ctx)
+
+	if opts.skipExistingConfigMaps && obj.GetKind() == "ConfigMap" {
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(obj.GroupVersionKind())
+		err := r.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+		if err == nil {
+			logger.Info("ConfigMap already exists, skipping update", "name", obj.GetName(), "namespace", obj.GetNamespace())
+			return nil
+		}
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get existing resource: %w", err)
+		}
+	}
+
+	patchOpts := []client.PatchOption{client.FieldOwner(fieldManager)}
+	if opts.forceOwnership {
+		patchOpts = append(patchOpts, client.ForceOwnership)
+	}
+
+	// The live object tells a create apart from an update, and an apply that
+	// changed nothing leaves its resourceVersion as it was
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	err := r.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get existing resource: %w", err)
+	}
+	created := errors.IsNotFound(err)
+
+	obj.SetManagedFields(nil)
+	obj.SetResourceVersion("")
+	err = r.Patch(ctx, obj, client.Apply, patchOpts...)
+	if err == nil {
+		if created && opts.trackCreated {
+			recordCreated(ctx, resourceRefFor(obj))
+		}
+		if created || obj.GetResourceVersion() != existing.GetResourceVersion() {
+			recordChanged(ctx)
+		}
+		return nil
+	}
+
+	if conflicts := fieldManagerConflicts(err); len(conflicts) > 0 {
+		ref := fmt.Sprintf("%s %s", obj.GetKind(), client.ObjectKeyFromObject(obj))
+		logger.Info("Field manager conflict, not forcing ownership", "resource", ref, "fields", conflicts)
+		if state := reconcileStateFrom(ctx); state != nil {
+			state.fieldConflicts = append(state.fieldConflicts, fmt.Sprintf("%s: %s", ref, strings.Join(conflicts, ", ")))
+		}
+	}
+	return fmt.Errorf("failed to apply resource: %w", err)
+}
+
+// fieldManagerConflicts extracts the conflicting fields from a server-side
+// apply conflict error
+func fieldManagerConflicts(err error) []string {
+	if !errors.IsConflict(err) {
+		return nil
+	}
+
+	var status errors.APIStatus
+	if !stderrors.As(err, &status) || status.Status().Details == nil {
+		return nil
+	}
+
+	conflicts := []string{}
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type == metav1.CauseTypeFieldManagerConflict {
+			conflicts = append(conflicts, fmt.Sprintf("%s (%s)", cause.Field, cause.Message))
+		}
+	}
+	return conflicts
+}
+
+// deleteResources deletes each referenced resource, ignoring ones already
+// gone. Resources retainedOnDelete keeps, and those another KServeDeployment
+// still manages (a shared CRD, say), are left in place and returned.
+func (r *KServeDeploymentReconciler) deleteResources(ctx context.Context, kd *platformv1alpha1.KServeDeployment, refs []platformv1alpha1.ResourceRef) []platformv1alpha1.ResourceRef {
+	logger := log.FromContext(ctx)
+
+	// Without knowing what the others manage, nothing is safe to delete
+	shared, err := r.sharedResources(ctx, kd)
+	if err != nil {
+		logger.Error(err, "Not deleting resources another KServeDeployment may manage", "count", len(refs))
+		return refs
+	}
+
+	retained := []platformv1alpha1.ResourceRef{}
+	for _, ref := range refs {
+		if retainedOnDelete(kd, ref) {
+			logger.Info("Keeping resource", "kind", ref.Kind, "name", ref.Name, "namespace", ref.Namespace)
+			retained = append(retained, ref)
+			continue
+		}
+		if shared[resourceKeyFor(ref)] {
+			logger.Info("Keeping resource managed by another KServeDeployment", "kind", ref.Kind, "name", ref.Name, "namespace", ref.Namespace)
+			retained = append(retained, ref)
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion(ref.APIVersion)
+		obj.SetKind(ref.Kind)
+		obj.SetNamespace(ref.Namespace)
+		obj.SetName(ref.Name)
+
+		logger.Info("Deleting resource", "kind", ref.Kind, "name", ref.Name, "namespace", ref.Namespace)
+		if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete resource", "kind", ref.Kind, "name", ref.Name)
+		}
+	}
+	return retained
+}
+
+// retainedOnDelete reports whether deleting ref would take more with it than
+// the KServeDeployment owns. CRDs, whose deletion removes every custom
+// resource of their kind, are only deleted with DeleteCRDsOnCleanup.
+// Namespaces are never deleted here; the ones the operator created are left
+// to deleteCreatedNamespaces, which checks they hold nothing else.
+func retainedOnDelete(kd *platformv1alpha1.KServeDeployment, ref platformv1alpha1.ResourceRef) bool {
+	switch schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind).GroupKind() {
+	case schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}:
+		return !kd.Spec.DeleteCRDsOnCleanup
+	case schema.GroupKind{Group: "", Kind: "Namespace"}:
+		return true
+	}
+	return false
+}
+
+func resourceRefFor(obj *unstructured.Unstructured) platformv1alpha1.ResourceRef {
+	return platformv1alpha1.ResourceRef{
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+// contentHash returns a stable hash of the desired state of obj, ignoring any
+// previously stamped hash and apply time annotations
+func contentHash(obj *unstructured.Unstructured) (string, error) {
+	desired := obj.DeepCopy()
+	annotations := desired.GetAnnotations()
+	delete(annotations, contentHashAnnotation)
+	delete(annotations, appliedAtAnnotation)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	desired.SetAnnotations(annotations)
+
+	// encoding/json sorts map keys, so equal objects hash equally
+	data, err := json.Marshal(desired.Object)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// stampContentHash sets the content hash annotation on obj and returns the hash
+func stampContentHash(obj *unstructured.Unstructured) (string, error) {
+	hash, err := contentHash(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash resource: %w", err)
+	}
+	setAnnotation(obj, contentHashAnnotation, hash)
+	return hash, nil
+}
+
+func setLabel(obj *unstructured.Unstructured, key, value string) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[key] = value
+	obj.SetLabels(labels)
+}
+
+func setAnnotation(obj *unstructured.Unstructured, key, value string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[key] = value
+	obj.SetAnnotations(annotations)
+}
+
+// takeKeepExisting removes the keep-existing annotation from obj, reporting
+// whether it was set
+func takeKeepExisting(obj *unstructured.Unstructured) bool {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[keepExistingAnnotation]; !ok {
+		return false
+	}
+	delete(annotations, keepExistingAnnotation)
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// stampRendered puts obj in the form rendered manifests take: with its
+// content hash, and marked keep-existing when it is a ConfigMap its source
+// leaves alone once it exists
+func stampRendered(obj *unstructured.Unstructured, keepExisting bool) error {
+	if _, err := stampContentHash(obj); err != nil {
+		return err
+	}
+	if keepExisting && obj.GetKind() == "ConfigMap" {
+		setAnnotation(obj, keepExistingAnnotation, "true")
+	}
+	return nil
+}
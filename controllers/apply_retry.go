@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// applyRetryBackoff bounds the retries of a single resource: five attempts
+// over roughly 7.5s
+var applyRetryBackoff = wait.Backoff{
+	Steps:    5,
+	Duration: 500 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+}
+
+// applyObjectWithRetry applies obj, retrying transient failures with backoff
+// so one hiccup doesn't fail the whole component. Each attempt starts from a
+// fresh copy of obj, and each retry is drawn from the shared RetryBudget.
+func (r *KServeDeploymentReconciler) applyObjectWithRetry(ctx context.Context, obj *unstructured.Unstructured, opts applyOptions) error {
+	logger := log.FromContext(ctx)
+
+	attempt := 0
+	return retry.OnError(applyRetryBackoff, func(err error) bool {
+		if shuttingDown(ctx) || !retryableApplyError(err) {
+			return false
+		}
+		if !r.RetryBudget.Take() {
+			logger.Info("Retry budget exhausted, leaving resource for a later reconcile",
+				"kind", obj.GetKind(), "name", obj.GetName(), "namespace", obj.GetNamespace(), "error", err.Error())
+			recordRetryDenied(ctx)
+			return false
+		}
+		logger.Info("Retrying resource after transient error",
+			"kind", obj.GetKind(), "name", obj.GetName(), "namespace", obj.GetNamespace(),
+			"attempt", attempt, "error", err.Error())
+		return true
+	}, func() error {
+		attempt++
+		return r.applyObject(ctx, obj.DeepCopy(), opts)
+	})
+}
+
+// retryableApplyError reports whether an apply failed for a reason that
+// usually clears up on its own: an update conflict, an overloaded or timed
+// out API server, or an admission webhook that isn't serving yet. Field
+// manager conflicts under server-side apply need a decision, not a retry.
+func retryableApplyError(err error) bool {
+	switch {
+	case errors.IsConflict(err):
+		return len(fieldManagerConflicts(err)) == 0
+	case errors.IsServerTimeout(err), errors.IsTimeout(err), errors.IsTooManyRequests(err), errors.IsServiceUnavailable(err):
+		return true
+	case errors.IsInternalError(err):
+		return strings.Contains(err.Error(), "failed calling webhook")
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+const unchangedManifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: kserve
+data:
+  mode: RawDeployment
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: kserve-webhook-server-service
+  namespace: kserve
+spec:
+  ports:
+  - port: 443
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: kserve-controller-manager
+  namespace: kserve
+spec:
+  selector:
+    matchLabels:
+      app: kserve
+  template:
+    metadata:
+      labels:
+        app: kserve
+    spec:
+      containers:
+      - name: manager
+        image: kserve/kserve-controller:v0.11.0
+`
+
+func TestReapplyingAnUnchangedManifestIssuesNoUpdates(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	writes := 0
+	c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+			writes++
+			return c.Update(ctx, obj, opts...)
+		},
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			writes++
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+	}).Build()
+	r := &KServeDeploymentReconciler{Client: c}
+
+	// Each apply stands in for a reconcile, with state of its own
+	first := withReconcileState(context.Background(), time.Now())
+	if _, err := r.applyManifests(first, []byte(unchangedManifest), applyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if changedCount(first) != 3 {
+		t.Fatalf("first apply changed %d resources, want 3", changedCount(first))
+	}
+
+	writes = 0
+	second := withReconcileState(context.Background(), time.Now())
+	applied, err := r.applyManifests(second, []byte(unchangedManifest), applyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 3 {
+		t.Fatalf("second apply recorded %d resources, want 3", len(applied))
+	}
+	if writes != 0 {
+		t.Errorf("second apply issued %d updates, want none", writes)
+	}
+	if changedCount(second) != 0 {
+		t.Errorf("second apply reported %d changed resources, want none", changedCount(second))
+	}
+}
+
+const mixedNamespaceManifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: defaulted
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: explicit
+  namespace: elsewhere
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: cluster-scoped
+`
+
+func TestDefaultNamespaceOnlyFillsInMissingNamespaces(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	core := schema.GroupVersion{Version: "v1"}
+	rbac := schema.GroupVersion{Group: "rbac.authorization.k8s.io", Version: "v1"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{core, rbac})
+	mapper.Add(core.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	mapper.Add(rbac.WithKind("ClusterRole"), meta.RESTScopeRoot)
+	r := &KServeDeploymentReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).Build()}
+
+	ctx := withReconcileState(context.Background(), time.Now())
+	applied, err := r.applyManifests(ctx, []byte(mixedNamespaceManifest), applyOptions{defaultNamespace: "kserve"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{"defaulted": "kserve", "explicit": "elsewhere", "cluster-scoped": ""}
+	if len(applied) != len(want) {
+		t.Fatalf("applied %d resources, want %d", len(applied), len(want))
+	}
+	for _, ref := range applied {
+		if ref.Namespace != want[ref.Name] {
+			t.Errorf("%s %s was applied in namespace %q, want %q", ref.Kind, ref.Name, ref.Namespace, want[ref.Name])
+		}
+	}
+}
+
+const clusterScopedManifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: cluster-scoped
+`
+
+func TestSingleNamespaceModeRejectsClusterScopedObjects(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	core := schema.GroupVersion{Version: "v1"}
+	rbac := schema.GroupVersion{Group: "rbac.authorization.k8s.io", Version: "v1"}
+	mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{core, rbac})
+	mapper.Add(core.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	mapper.Add(rbac.WithKind("ClusterRole"), meta.RESTScopeRoot)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).Build()
+	r := &KServeDeploymentReconciler{Client: c, WatchNamespace: "kserve"}
+
+	ctx := withReconcileState(context.Background(), time.Now())
+	_, err := r.applyManifests(ctx, []byte(clusterScopedManifest), applyOptions{defaultNamespace: "kserve"})
+	if err == nil {
+		t.Fatal("applying a ClusterRole in single-namespace mode succeeded")
+	}
+	if reason := failureReason(err); reason != platformv1alpha1.ReasonScopeConflict {
+		t.Errorf("failure reason = %s, want %s", reason, platformv1alpha1.ReasonScopeConflict)
+	}
+
+	if err := c.Get(ctx, client.ObjectKey{Name: "cluster-scoped"}, &rbacv1.ClusterRole{}); !errors.IsNotFound(err) {
+		t.Errorf("the ClusterRole was applied: %v", err)
+	}
+}
+
+func TestDeleteResourcesKeepsWhatAnotherDeploymentManages(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := platformv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	sharedRef := platformv1alpha1.ResourceRef{APIVersion: "v1", Kind: "ConfigMap", Namespace: "kserve", Name: "shared"}
+	ownRef := platformv1alpha1.ResourceRef{APIVersion: "v1", Kind: "ConfigMap", Namespace: "kserve", Name: "own"}
+	kd := &platformv1alpha1.KServeDeployment{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: "platform"}}
+	kd.Status.ManagedResources = []platformv1alpha1.ResourceRef{sharedRef, ownRef}
+	other := &platformv1alpha1.KServeDeployment{ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: "platform"}}
+	other.Status.ManagedResources = []platformv1alpha1.ResourceRef{sharedRef}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		kd, other,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "kserve"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "own", Namespace: "kserve"}},
+	).Build()
+	r := &KServeDeploymentReconciler{Client: c}
+
+	ctx := context.Background()
+	retained := r.deleteResources(ctx, kd, kd.Status.ManagedResources)
+	if len(retained) != 1 || retained[0] != sharedRef {
+		t.Errorf("retained = %v, want only the shared ConfigMap", retained)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "kserve", Name: "shared"}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("the shared ConfigMap was deleted: %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "kserve", Name: "own"}, &corev1.ConfigMap{}); !errors.IsNotFound(err) {
+		t.Errorf("the ConfigMap only this deployment manages wasn't deleted: %v", err)
+	}
+}
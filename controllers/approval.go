@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// approveAnnotation approves the current spec when RequireApproval is set.
+// Its value names the approver. The operator records the approval in status
+// and removes the annotation, so the next spec change needs a new one.
+const approveAnnotation = "platform.ai-platform.io/approve"
+
+// awaitingApproval reports whether the current spec still needs approval
+// before it is installed. A pending approval annotation is recorded and
+// cleared here.
+func (r *KServeDeploymentReconciler) awaitingApproval(ctx context.Context, kd *platformv1alpha1.KServeDeployment) (bool, error) {
+	hash := specHash(kd)
+	if !kd.Spec.RequireApproval || (kd.Status.Approval != nil && kd.Status.Approval.SpecHash == hash) {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "AwaitingApproval")
+		return false, nil
+	}
+
+	approver := strings.TrimSpace(kd.Annotations[approveAnnotation])
+	if approver == "" {
+		if !meta.IsStatusConditionTrue(kd.Status.Conditions, "AwaitingApproval") {
+			r.recordEvent(kd, corev1.EventTypeNormal, "ApprovalRequired",
+				fmt.Sprintf("Spec change needs approval: annotate with %s=<approver>", approveAnnotation))
+		}
+		meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+			Type:               "AwaitingApproval",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: kd.Generation,
+			Reason:             platformv1alpha1.ReasonApprovalRequired,
+			Message:            fmt.Sprintf("Waiting for the %s annotation before installing this spec", approveAnnotation),
+		})
+		return true, nil
+	}
+
+	// Clear the annotation on a copy so the in-memory object keeps the
+	// changes this reconcile already made
+	approved := kd.DeepCopy()
+	delete(approved.Annotations, approveAnnotation)
+	if err := r.Patch(ctx, approved, client.MergeFrom(kd)); err != nil {
+		return false, fmt.Errorf("failed to clear %s annotation: %w", approveAnnotation, err)
+	}
+	kd.Annotations = approved.Annotations
+	kd.ResourceVersion = approved.ResourceVersion
+
+	log.FromContext(ctx).Info("Spec approved", "approver", approver)
+	kd.Status.Approval = &platformv1alpha1.ApprovalStatus{
+		ApprovedBy: approver,
+		ApprovedAt: metav1.Now(),
+		SpecHash:   hash,
+	}
+	meta.RemoveStatusCondition(&kd.Status.Conditions, "AwaitingApproval")
+
+	// Time the install from the approval rather than from the spec change
+	startedAt := kd.Status.Approval.ApprovedAt
+	kd.Status.InstallStartedAt = &startedAt
+	kd.Status.ReadyDuration = nil
+
+	r.recordEvent(kd, corev1.EventTypeNormal, "ApprovalGranted", fmt.Sprintf("Spec approved by %s", approver))
+	return false, nil
+}
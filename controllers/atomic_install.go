@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// recordCreated notes a resource created (rather than updated) during the
+// current reconcile, so an atomic install can roll it back
+func recordCreated(ctx context.Context, ref platformv1alpha1.ResourceRef) {
+	if state := reconcileStateFrom(ctx); state != nil {
+		state.created = append(state.created, ref)
+	}
+}
+
+// deployComponentAtomically deploys component. With AtomicInstall set, a
+// resource that fails to apply fails the component, and a failed component
+// has the resources it created deleted again so it isn't left half-applied.
+// Resources that existed before are left as they are, and so are the CRDs and
+// namespaces retainedOnDelete keeps.
+func (r *KServeDeploymentReconciler) deployComponentAtomically(ctx context.Context, kd *platformv1alpha1.KServeDeployment, component string) error {
+	state := reconcileStateFrom(ctx)
+	if !kd.Spec.AtomicInstall || state == nil {
+		return r.deployComponent(ctx, kd, component)
+	}
+
+	createdBefore, failedBefore := len(state.created), len(state.failed)
+	err := r.deployComponent(ctx, kd, component)
+	if err == nil && len(state.failed) > failedBefore {
+		err = fmt.Errorf("component %s: %d resources failed to apply", component, len(state.failed)-failedBefore)
+	}
+	if err == nil || shuttingDown(ctx) {
+		// An interrupted install resumes from its checkpoint instead
+		return err
+	}
+
+	created := state.created[createdBefore:]
+	log.FromContext(ctx).Info("Rolling back failed component", "component", component, "created", len(created))
+	retained := r.deleteResources(ctx, kd, reverseResourceRefs(created))
+
+	// Retained resources stay managed, so a later cleanup can still delete them
+	rolledBack := map[platformv1alpha1.ResourceRef]bool{}
+	for _, ref := range created {
+		rolledBack[ref] = true
+	}
+	for _, ref := range retained {
+		rolledBack[ref] = false
+	}
+	applied := []platformv1alpha1.ResourceRef{}
+	for _, ref := range state.applied {
+		if !rolledBack[ref] {
+			applied = append(applied, ref)
+		}
+	}
+	state.applied = applied
+	state.created = state.created[:createdBefore]
+
+	return err
+}
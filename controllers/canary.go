@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// canaryLabel marks a KServeDeployment as a canary. Upgrades of the rest of
+// the fleet wait until the canaries have been Ready at the new version for
+// the canary period.
+const canaryLabel = "platform.ai-platform.io/canary"
+
+// canaryPollInterval is how often a held upgrade checks on canaries that
+// aren't Ready at its version yet
+const canaryPollInterval = time.Minute
+
+// isCanary reports whether kd is labelled as a canary
+func isCanary(kd *platformv1alpha1.KServeDeployment) bool {
+	return kd.Labels[canaryLabel] == "true"
+}
+
+// checkCanaries holds the upgrade of a non-canary deployment until every
+// canary targeting the same version has been Ready at it for CanaryPeriod.
+// It returns how long to wait before checking again, or zero to go ahead.
+// Fresh installs, and fleets without canaries, are never held.
+func (r *KServeDeploymentReconciler) checkCanaries(ctx context.Context, kd *platformv1alpha1.KServeDeployment) (time.Duration, string, error) {
+	if isCanary(kd) {
+		meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+			Type:               "Canary",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: kd.Generation,
+			Reason:             platformv1alpha1.ReasonCanaryLabel,
+			Message:            "Upgrades of non-canary deployments wait for this one",
+		})
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "CanaryHold")
+		return 0, "", nil
+	}
+	meta.RemoveStatusCondition(&kd.Status.Conditions, "Canary")
+
+	// InstalledVersion survives failed installs, so only a deployment that
+	// never installed counts as fresh
+	target := kd.Spec.Version
+	if kd.Status.InstalledVersion == "" || kd.Status.InstalledVersion == target {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "CanaryHold")
+		return 0, "", nil
+	}
+
+	list := &platformv1alpha1.KServeDeploymentList{}
+	if err := r.List(ctx, list, client.MatchingLabels{canaryLabel: "true"}); err != nil {
+		return 0, "", fmt.Errorf("failed to list canary deployments: %w", err)
+	}
+	if len(list.Items) == 0 {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "CanaryHold")
+		return 0, "", nil
+	}
+
+	now := time.Now()
+	wait := time.Duration(0)
+	pending := []string{}
+	for i := range list.Items {
+		canary := &list.Items[i]
+		if canary.Spec.Version != target {
+			continue
+		}
+		remaining := r.canaryRemaining(canary, now)
+		if remaining <= 0 {
+			continue
+		}
+		pending = append(pending, client.ObjectKeyFromObject(canary).String())
+		if wait == 0 || remaining < wait {
+			wait = remaining
+		}
+	}
+
+	if len(pending) == 0 && canariesTarget(list.Items, target) {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "CanaryHold")
+		return 0, "", nil
+	}
+
+	var message string
+	reason := platformv1alpha1.ReasonWaitingForCanaries
+	if len(pending) == 0 {
+		// Canaries exist but none has tried this version yet
+		wait = canaryPollInterval
+		reason = platformv1alpha1.ReasonNoCanaryForVersion
+		message = fmt.Sprintf("Upgrade to %s is held because no canary deployment targets it; set a canary's spec.version to %s, or remove the canary label from all deployments",
+			target, target)
+	} else {
+		sort.Strings(pending)
+		message = fmt.Sprintf("Upgrade to %s is held until canaries %s have been Ready at it for %s",
+			target, strings.Join(pending, ", "), r.CanaryPeriod)
+	}
+	meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+		Type:               "CanaryHold",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kd.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	return wait, message, nil
+}
+
+// canaryRemaining returns how much longer canary has to stay Ready at its
+// version before others follow, or canaryPollInterval when it isn't Ready
+// at it yet
+func (r *KServeDeploymentReconciler) canaryRemaining(canary *platformv1alpha1.KServeDeployment, now time.Time) time.Duration {
+	status := canary.Status
+	if status.Phase != "Ready" || status.InstalledVersion != canary.Spec.Version ||
+		status.InstallStartedAt == nil || status.ReadyDuration == nil {
+		return canaryPollInterval
+	}
+
+	readyAt := status.InstallStartedAt.Add(status.ReadyDuration.Duration)
+	return r.CanaryPeriod - now.Sub(readyAt)
+}
+
+func canariesTarget(canaries []platformv1alpha1.KServeDeployment, version string) bool {
+	for i := range canaries {
+		if canaries[i].Spec.Version == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SourceUnavailableError is returned when a manifest source's circuit is open
+type SourceUnavailableError struct {
+	Source     string
+	RetryAfter time.Duration
+}
+
+func (e *SourceUnavailableError) Error() string {
+	return fmt.Sprintf("manifest source %s is unavailable, retrying in %s", e.Source, e.RetryAfter.Round(time.Second))
+}
+
+// asSourceUnavailable reports whether err was caused by an open circuit
+func asSourceUnavailable(err error) (*SourceUnavailableError, bool) {
+	var unavailable *SourceUnavailableError
+	ok := errors.As(err, &unavailable)
+	return unavailable, ok
+}
+
+// CircuitBreaker tracks consecutive fetch failures per manifest source. After
+// Threshold failures the circuit opens and fetches short-circuit for Cooldown,
+// after which a single half-open probe decides whether it closes again. One
+// breaker is shared by all KServeDeployment objects.
+type CircuitBreaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu      sync.Mutex
+	sources map[string]*sourceState
+}
+
+type sourceState struct {
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker returns a breaker that opens after threshold consecutive failures
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		Threshold: threshold,
+		Cooldown:  cooldown,
+		sources:   map[string]*sourceState{},
+	}
+}
+
+// Allow returns a SourceUnavailableError while the circuit for source is open.
+// Once the cooldown has passed exactly one caller is let through as a probe.
+func (b *CircuitBreaker) Allow(source string) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, ok := b.sources[source]
+	if !ok || state.failures < b.Threshold {
+		return nil
+	}
+
+	remaining := b.Cooldown - time.Since(state.openedAt)
+	if remaining > 0 {
+		return &SourceUnavailableError{Source: source, RetryAfter: remaining}
+	}
+
+	// Half-open: allow a single probe, keep everyone else waiting on it
+	if state.probing {
+		return &SourceUnavailableError{Source: source, RetryAfter: b.Cooldown}
+	}
+	state.probing = true
+	return nil
+}
+
+// Record updates the circuit for source with the outcome of a fetch
+func (b *CircuitBreaker) Record(source string, err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		delete(b.sources, source)
+		return
+	}
+
+	state, ok := b.sources[source]
+	if !ok {
+		state = &sourceState{}
+		b.sources[source] = state
+	}
+
+	state.failures++
+	state.probing = false
+	if state.failures >= b.Threshold {
+		// Opening (or re-opening after a failed probe) restarts the cooldown
+		state.openedAt = time.Now()
+	}
+}
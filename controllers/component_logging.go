@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// logLevelAnnotation overrides the log level of single components, as a
+// comma-separated list of component=level, e.g. "kserve=debug,cert-manager=info".
+// Extra manifests are named manifest/<name>.
+const logLevelAnnotation = "platform.ai-platform.io/log-level"
+
+// namedLogLevels are the levels that can be given by name. error leaves only
+// errors; higher verbosities can be given as numbers.
+var namedLogLevels = map[string]int{
+	"error": -1,
+	"info":  0,
+	"debug": 1,
+}
+
+// componentLogLevels parses the log level annotation into the verbosity of
+// each component. Invalid entries are skipped and reported in the error, so
+// a typo only loses its own override.
+func componentLogLevels(kd *platformv1alpha1.KServeDeployment) (map[string]int, error) {
+	levels := map[string]int{}
+	value := strings.TrimSpace(kd.Annotations[logLevelAnnotation])
+	if value == "" {
+		return levels, nil
+	}
+
+	errs := []error{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		component, level, ok := strings.Cut(entry, "=")
+		component, level = strings.TrimSpace(component), strings.TrimSpace(level)
+		if !ok || component == "" {
+			errs = append(errs, fmt.Errorf("%s entry %q is not component=level", logLevelAnnotation, entry))
+			continue
+		}
+		manifest, isManifest := strings.CutPrefix(component, extraManifestPrefix)
+		if !hasComponent(kd, component) && !(isManifest && hasExtraManifest(kd, manifest)) {
+			errs = append(errs, fmt.Errorf("%s names component %s, which is not in spec.components or spec.extraManifests", logLevelAnnotation, component))
+			continue
+		}
+		verbosity, err := parseLogLevel(level)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s entry %q: %w", logLevelAnnotation, entry, err))
+			continue
+		}
+		levels[component] = verbosity
+	}
+	return levels, stderrors.Join(errs...)
+}
+
+// parseLogLevel turns a level name or a non-negative verbosity into the
+// highest V level that is logged
+func parseLogLevel(level string) (int, error) {
+	if verbosity, ok := namedLogLevels[strings.ToLower(level)]; ok {
+		return verbosity, nil
+	}
+	verbosity, err := strconv.Atoi(level)
+	if err != nil || verbosity < 0 {
+		return 0, fmt.Errorf("level must be error, info, debug or a verbosity >= 0, not %q", level)
+	}
+	return verbosity, nil
+}
+
+// withComponentLogger returns ctx with a logger at the level configured for
+// component, so the helpers deploying it, which log through
+// log.FromContext, follow the override. Without an override ctx is returned
+// unchanged.
+func withComponentLogger(ctx context.Context, kd *platformv1alpha1.KServeDeployment, component string) context.Context {
+	levels, _ := componentLogLevels(kd)
+	verbosity, ok := levels[component]
+	if !ok {
+		return ctx
+	}
+
+	logger := log.FromContext(ctx)
+	sink := logger.GetSink()
+	if sink == nil {
+		return ctx
+	}
+	if leveled, ok := sink.(leveledSink); ok {
+		sink = leveled.sink
+	} else if withDepth, ok := sink.(logr.CallDepthLogSink); ok {
+		// Skip the wrapper's frame so callers are still reported correctly
+		sink = withDepth.WithCallDepth(1)
+	}
+	return log.IntoContext(ctx, logger.WithSink(leveledSink{sink: sink, verbosity: verbosity}))
+}
+
+// leveledSink filters a LogSink at its own verbosity instead of the
+// operator's. Messages above V(0) that pass are handed on at V(0) with their
+// level in a "v" field, so a component can log at debug while the operator
+// logs at info.
+type leveledSink struct {
+	sink      logr.LogSink
+	verbosity int
+}
+
+func (s leveledSink) Init(info logr.RuntimeInfo) {
+	s.sink.Init(info)
+}
+
+func (s leveledSink) Enabled(level int) bool {
+	return level <= s.verbosity
+}
+
+func (s leveledSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	if level > 0 {
+		keysAndValues = append(append([]interface{}{}, keysAndValues...), "v", level)
+	}
+	s.sink.Info(0, msg, keysAndValues...)
+}
+
+func (s leveledSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.sink.Error(err, msg, keysAndValues...)
+}
+
+func (s leveledSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return leveledSink{sink: s.sink.WithValues(keysAndValues...), verbosity: s.verbosity}
+}
+
+func (s leveledSink) WithName(name string) logr.LogSink {
+	return leveledSink{sink: s.sink.WithName(name), verbosity: s.verbosity}
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// setComponentStatus records that component was applied at the desired
+// version by this operator build. LastAppliedTime only moves when changed
+// reports that the apply created or modified a resource, so a reconcile that
+// finds everything in place doesn't rewrite status.
+func (r *KServeDeploymentReconciler) setComponentStatus(kd *platformv1alpha1.KServeDeployment, component string, changed bool) {
+	status := platformv1alpha1.ComponentStatus{
+		Name:                       component,
+		Version:                    kd.Spec.Version,
+		InstalledByOperatorVersion: r.OperatorVersion,
+		LastAppliedTime:            metav1.Now(),
+	}
+
+	for i := range kd.Status.ComponentStatuses {
+		if kd.Status.ComponentStatuses[i].Name == component {
+			if !changed {
+				status.LastAppliedTime = kd.Status.ComponentStatuses[i].LastAppliedTime
+			}
+			kd.Status.ComponentStatuses[i] = status
+			return
+		}
+	}
+	kd.Status.ComponentStatuses = append(kd.Status.ComponentStatuses, status)
+}
+
+// hasComponent reports whether component is requested
+func hasComponent(kd *platformv1alpha1.KServeDeployment, component string) bool {
+	for _, c := range kd.Spec.Components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneComponentStatuses drops entries for components no longer requested
+func pruneComponentStatuses(kd *platformv1alpha1.KServeDeployment) {
+	requested := map[string]bool{}
+	for _, component := range kd.Spec.Components {
+		requested[component] = true
+	}
+
+	kept := []platformv1alpha1.ComponentStatus{}
+	for _, status := range kd.Status.ComponentStatuses {
+		if requested[status.Name] {
+			kept = append(kept, status)
+		}
+	}
+	kd.Status.ComponentStatuses = kept
+}
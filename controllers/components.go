@@ -0,0 +1,19 @@
+package controllers
+
+// uniqueStrings returns values without repeats, keeping the first occurrence
+// of each in order
+func uniqueStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	unique := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	stderrors "errors"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// ReasonError tags the error a step failed with with the condition reason
+// the failure is reported under
+type ReasonError struct {
+	Reason string
+	Err    error
+}
+
+func (e *ReasonError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ReasonError) Unwrap() error {
+	return e.Err
+}
+
+// withReason tags err with reason, leaving a nil err nil
+func withReason(reason string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ReasonError{Reason: reason, Err: err}
+}
+
+// failureReason returns the reason a failed reconcile is reported under.
+// The innermost reason in the error chain wins, as it names the step that
+// actually failed: a checksum mismatch while applying a component is a
+// ChecksumMismatch, not an ApplyFailed.
+func failureReason(err error) string {
+	reason := platformv1alpha1.ReasonReconcileFailed
+	for ; err != nil; err = stderrors.Unwrap(err) {
+		switch e := err.(type) {
+		case *ChecksumMismatchError:
+			return platformv1alpha1.ReasonChecksumMismatch
+		case *SourceUnavailableError:
+			return platformv1alpha1.ReasonManifestFetchFailed
+		case *ReasonError:
+			reason = e.Reason
+		}
+	}
+	return reason
+}
+
+// phaseReason is the Ready condition's reason for a phase when the caller
+// doesn't give a more specific one
+func phaseReason(phase string) string {
+	switch phase {
+	case "Ready":
+		return platformv1alpha1.ReasonInstallComplete
+	case "Pending":
+		return platformv1alpha1.ReasonApprovalRequired
+	case "Terminating":
+		return platformv1alpha1.ReasonTerminating
+	case "Degraded":
+		return platformv1alpha1.ReasonPostInstallJobFailed
+	case "Failed":
+		return platformv1alpha1.ReasonReconcileFailed
+	default:
+		return platformv1alpha1.ReasonInstalling
+	}
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// crdEstablishTimeout bounds how long applying a manifest waits for its
+	// CRDs to be served before applying the rest of it
+	crdEstablishTimeout = 30 * time.Second
+
+	crdPollInterval = time.Second
+)
+
+var crdGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+func isCRD(obj *unstructured.Unstructured) bool {
+	return obj.GroupVersionKind().GroupKind() == crdGVK.GroupKind()
+}
+
+// crdsFirst moves CRDs ahead of the other objects, keeping the relative order
+// of both, so custom resources are never applied before their definitions
+func crdsFirst(objs []unstructured.Unstructured) []unstructured.Unstructured {
+	ordered := make([]unstructured.Unstructured, 0, len(objs))
+	for i := range objs {
+		if isCRD(&objs[i]) {
+			ordered = append(ordered, objs[i])
+		}
+	}
+	for i := range objs {
+		if !isCRD(&objs[i]) {
+			ordered = append(ordered, objs[i])
+		}
+	}
+	return ordered
+}
+
+// establishCRDs waits for the named CRDs to be Established. The client's
+// RESTMapper is controller-runtime's lazy dynamic mapper, which re-discovers
+// a group whenever a kind doesn't match, so custom resources applied next in
+// the same pass resolve the new kinds without a reset. A CRD that doesn't
+// become Established in time is logged; its resources fail to apply and are
+// retried on the next reconcile.
+func (r *KServeDeploymentReconciler) establishCRDs(ctx context.Context, names []string) {
+	logger := log.FromContext(ctx)
+
+	for _, name := range names {
+		err := wait.PollUntilContextTimeout(ctx, crdPollInterval, crdEstablishTimeout, true, func(ctx context.Context) (bool, error) {
+			return r.crdEstablished(ctx, name)
+		})
+		if err != nil {
+			logger.Info("CRD not established yet", "name", name, "error", err.Error())
+		}
+	}
+}
+
+// crdEstablished reports whether the API server serves the named CRD
+func (r *KServeDeploymentReconciler) crdEstablished(ctx context.Context, name string) (bool, error) {
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	if err := r.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == "Established" && condition["status"] == "True" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
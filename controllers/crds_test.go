@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+const widgetManifest = `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: sprocket
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+    plural: widgets
+  scope: Namespaced
+  versions:
+  - name: v1
+    served: true
+    storage: true
+`
+
+// discoveryServer serves API discovery for the CRD API, and for example.com
+// once served is set, the way an API server starts serving a CRD's group
+// after it is established
+func discoveryServer(t *testing.T, served *atomic.Bool) *httptest.Server {
+	resources := map[string]metav1.APIResourceList{
+		"/apis/apiextensions.k8s.io/v1": {
+			GroupVersion: "apiextensions.k8s.io/v1",
+			APIResources: []metav1.APIResource{{Name: "customresourcedefinitions", Kind: "CustomResourceDefinition", Verbs: metav1.Verbs{"get", "list", "create"}}},
+		},
+		"/apis/example.com/v1": {
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{{Name: "widgets", Kind: "Widget", Namespaced: true, Verbs: metav1.Verbs{"get", "list", "create"}}},
+		},
+	}
+
+	group := func(name string) metav1.APIGroup {
+		version := metav1.GroupVersionForDiscovery{GroupVersion: name + "/v1", Version: "v1"}
+		return metav1.APIGroup{Name: name, Versions: []metav1.GroupVersionForDiscovery{version}, PreferredVersion: version}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body interface{}
+		switch req.URL.Path {
+		case "/api":
+			body = metav1.APIVersions{Versions: []string{"v1"}}
+		case "/apis":
+			groups := metav1.APIGroupList{Groups: []metav1.APIGroup{group("apiextensions.k8s.io")}}
+			if served.Load() {
+				groups.Groups = append(groups.Groups, group("example.com"))
+			}
+			body = groups
+		default:
+			list, ok := resources[req.URL.Path]
+			if !ok || (list.GroupVersion == "example.com/v1" && !served.Load()) {
+				http.NotFound(w, req)
+				return
+			}
+			body = list
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(body); err != nil {
+			t.Errorf("failed to write discovery response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestApplyManifestsResolvesKindsOfCRDsInTheSamePass(t *testing.T) {
+	served := &atomic.Bool{}
+	server := discoveryServer(t, served)
+	mapper, err := apiutil.NewDynamicRESTMapper(&rest.Config{Host: server.URL}, server.Client())
+	if err != nil {
+		t.Fatalf("failed to create RESTMapper: %v", err)
+	}
+
+	widget := schema.GroupKind{Group: "example.com", Kind: "Widget"}
+	if _, err := mapper.RESTMapping(widget); err == nil {
+		t.Fatalf("Widget resolved before its CRD was applied")
+	}
+
+	// Creating the CRD establishes it and has its group served
+	c := fake.NewClientBuilder().
+		WithScheme(runtime.NewScheme()).
+		WithRESTMapper(mapper).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				if u, ok := obj.(*unstructured.Unstructured); ok && isCRD(u) {
+					conditions := []interface{}{map[string]interface{}{"type": "Established", "status": "True"}}
+					if err := unstructured.SetNestedSlice(u.Object, conditions, "status", "conditions"); err != nil {
+						return err
+					}
+					served.Store(true)
+				}
+				return c.Create(ctx, obj, opts...)
+			},
+		}).
+		Build()
+	r := &KServeDeploymentReconciler{Client: c}
+
+	applied, err := r.applyManifests(context.Background(), []byte(widgetManifest), applyOptions{defaultNamespace: "models"})
+	if err != nil {
+		t.Fatalf("applyManifests failed: %v", err)
+	}
+	if len(applied) != 2 || applied[0].Kind != "CustomResourceDefinition" || applied[1].Kind != "Widget" {
+		t.Fatalf("applied %v, want the CRD and then the Widget", applied)
+	}
+
+	// The Widget only gets the default namespace if its kind resolved
+	got := &unstructured.Unstructured{}
+	got.SetGroupVersionKind(widget.WithVersion("v1"))
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "models", Name: "sprocket"}, got); err != nil {
+		t.Fatalf("Widget was not created in the default namespace: %v", err)
+	}
+}
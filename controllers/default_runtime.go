@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+var (
+	servingRuntimeGVK        = schema.GroupVersionKind{Group: "serving.kserve.io", Version: "v1alpha1", Kind: "ServingRuntime"}
+	clusterServingRuntimeGVK = schema.GroupVersionKind{Group: "serving.kserve.io", Version: "v1alpha1", Kind: "ClusterServingRuntime"}
+)
+
+// setDefaultRuntime returns a mutator that sets runtime on InferenceServices
+// whose predictor model doesn't name one. Predictors with custom containers
+// have no model and are left alone.
+func setDefaultRuntime(runtimeName string) objectMutator {
+	return func(obj *unstructured.Unstructured) error {
+		if !needsDefaultRuntime(obj) {
+			return nil
+		}
+		return unstructured.SetNestedField(obj.Object, runtimeName, "spec", "predictor", "model", "runtime")
+	}
+}
+
+// needsDefaultRuntime reports whether obj is an InferenceService whose
+// predictor model doesn't name a runtime
+func needsDefaultRuntime(obj *unstructured.Unstructured) bool {
+	if obj.GetKind() != "InferenceService" {
+		return false
+	}
+	model, found, err := unstructured.NestedMap(obj.Object, "spec", "predictor", "model")
+	if err != nil || !found {
+		return false
+	}
+	runtime, _ := model["runtime"].(string)
+	return runtime == ""
+}
+
+// checkDefaultRuntimeFor runs checkDefaultRuntime in the namespace of each
+// InferenceService in manifest that gets the default runtime, since a
+// ServingRuntime only serves InferenceServices in its own namespace
+func (r *KServeDeploymentReconciler) checkDefaultRuntimeFor(ctx context.Context, kd *platformv1alpha1.KServeDeployment, manifest []byte) error {
+	if kd.Spec.DefaultRuntime == "" {
+		return nil
+	}
+
+	checked := map[string]bool{}
+	for _, obj := range decodeManifests(ctx, manifest) {
+		obj := obj
+		namespace := inferenceServiceNamespace(kd, &obj)
+		if !needsDefaultRuntime(&obj) || checked[namespace] {
+			continue
+		}
+		checked[namespace] = true
+		if err := r.checkDefaultRuntime(ctx, kd, namespace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inferenceServiceNamespace returns the namespace a KServe resource from a
+// manifest is applied in: its own, else the spec's default namespace, else
+// the deployment's
+func inferenceServiceNamespace(kd *platformv1alpha1.KServeDeployment, obj *unstructured.Unstructured) string {
+	switch {
+	case obj.GetNamespace() != "":
+		return obj.GetNamespace()
+	case kd.Spec.Namespace != "":
+		return kd.Spec.Namespace
+	default:
+		return kd.Namespace
+	}
+}
+
+// checkDefaultRuntime verifies the default runtime is installed, either as a
+// ServingRuntime in namespace or as a ClusterServingRuntime
+func (r *KServeDeploymentReconciler) checkDefaultRuntime(ctx context.Context, kd *platformv1alpha1.KServeDeployment, namespace string) error {
+	name := kd.Spec.DefaultRuntime
+	if name == "" {
+		return nil
+	}
+
+	for _, candidate := range []struct {
+		gvk schema.GroupVersionKind
+		key client.ObjectKey
+	}{
+		{servingRuntimeGVK, client.ObjectKey{Namespace: namespace, Name: name}},
+		{clusterServingRuntimeGVK, client.ObjectKey{Name: name}},
+	} {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(candidate.gvk)
+		err := r.Get(ctx, candidate.key, obj)
+		switch {
+		case err == nil:
+			return nil
+		case errors.IsNotFound(err), meta.IsNoMatchError(err):
+			continue
+		default:
+			return fmt.Errorf("failed to look up %s %s: %w", candidate.gvk.Kind, name, err)
+		}
+	}
+
+	return fmt.Errorf("default runtime %s is neither a ServingRuntime in namespace %s nor a ClusterServingRuntime", name, namespace)
+}
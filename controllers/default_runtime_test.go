@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+func TestDefaultRuntimeIsCheckedInTheInferenceServiceNamespace(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	servingRuntime := &unstructured.Unstructured{}
+	servingRuntime.SetGroupVersionKind(servingRuntimeGVK)
+	servingRuntime.SetNamespace("models")
+	servingRuntime.SetName("sklearn")
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(servingRuntime).Build()
+	r := &KServeDeploymentReconciler{Client: c, Scheme: scheme}
+
+	kd := &platformv1alpha1.KServeDeployment{ObjectMeta: metav1.ObjectMeta{Name: "kserve", Namespace: "platform"}}
+	kd.Spec.DefaultRuntime = "sklearn"
+	ctx := context.Background()
+
+	inModels := []byte(`apiVersion: serving.kserve.io/v1beta1
+kind: InferenceService
+metadata:
+  name: iris
+  namespace: models
+spec:
+  predictor:
+    model:
+      modelFormat:
+        name: sklearn
+`)
+	if err := r.checkDefaultRuntimeFor(ctx, kd, inModels); err != nil {
+		t.Fatalf("runtime in the InferenceService's namespace was not found: %v", err)
+	}
+
+	kd.Spec.Namespace = "serving"
+	defaulted := []byte(`apiVersion: serving.kserve.io/v1beta1
+kind: InferenceService
+metadata:
+  name: iris
+spec:
+  predictor:
+    model:
+      modelFormat:
+        name: sklearn
+`)
+	if err := r.checkDefaultRuntimeFor(ctx, kd, defaulted); err == nil {
+		t.Fatal("runtime in another namespace was accepted")
+	}
+
+	named := []byte(`apiVersion: serving.kserve.io/v1beta1
+kind: InferenceService
+metadata:
+  name: iris
+spec:
+  predictor:
+    model:
+      runtime: custom
+      modelFormat:
+        name: sklearn
+`)
+	if err := r.checkDefaultRuntimeFor(ctx, kd, named); err != nil {
+		t.Fatalf("an InferenceService naming its runtime was checked: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// checkDowngrade refuses a desired version older than the installed one
+// unless AllowDowngrade is set, since KServe doesn't reliably support going
+// back and a downgrade can leave stored resources unreadable. Versions that
+// don't parse aren't compared.
+func checkDowngrade(kd *platformv1alpha1.KServeDeployment) error {
+	installed, desired := kd.Status.InstalledVersion, kd.Spec.Version
+	if kd.Spec.AllowDowngrade || installed == "" || !versionLess(desired, installed) {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "DowngradeBlocked")
+		return nil
+	}
+
+	err := fmt.Errorf("version %s is older than the installed %s; set spec.allowDowngrade to downgrade", desired, installed)
+	meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+		Type:               "DowngradeBlocked",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kd.Generation,
+		Reason:             platformv1alpha1.ReasonDowngradeNotAllowed,
+		Message:            err.Error(),
+	})
+	return err
+}
+
+// versionLess reports whether version a is older than b
+func versionLess(a, b string) bool {
+	va, err := parseVersion(a)
+	if err != nil {
+		return false
+	}
+	vb, err := parseVersion(b)
+	if err != nil {
+		return false
+	}
+	return va.LessThan(vb)
+}
+
+// parseVersion parses a release version such as v0.11.2 or v0.12.0-rc1
+func parseVersion(v string) (*utilversion.Version, error) {
+	if parsed, err := utilversion.ParseSemantic(v); err == nil {
+		return parsed, nil
+	}
+	return utilversion.ParseGeneric(v)
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+const (
+	// managedByLabel is stamped on every applied resource
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "ai-platform-operator"
+
+	// kserveControllerDeployment is the Deployment every KServe install runs
+	kserveControllerDeployment = "kserve-controller-manager"
+)
+
+// checkExistingInstall looks for a KServe install the operator didn't make.
+// With AdoptExisting it is labelled as the operator's and then overwritten by
+// the regular apply; otherwise the install is refused with the
+// UnmanagedInstallDetected condition, so there are never two sources of
+// truth for one install.
+func (r *KServeDeploymentReconciler) checkExistingInstall(ctx context.Context, kd *platformv1alpha1.KServeDeployment) error {
+	key := client.ObjectKey{Namespace: componentNamespace(kd, "kserve"), Name: kserveControllerDeployment}
+
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, key, deployment); err != nil {
+		if errors.IsNotFound(err) {
+			meta.RemoveStatusCondition(&kd.Status.Conditions, "UnmanagedInstallDetected")
+			return nil
+		}
+		return fmt.Errorf("failed to look for an existing KServe install: %w", err)
+	}
+
+	if managedByOperator(deployment) {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "UnmanagedInstallDetected")
+		return nil
+	}
+
+	if !kd.Spec.AdoptExisting {
+		err := fmt.Errorf("KServe is already installed without the operator (Deployment %s); set spec.adoptExisting to take it over", key)
+		meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+			Type:               "UnmanagedInstallDetected",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: kd.Generation,
+			Reason:             platformv1alpha1.ReasonAdoptionDisabled,
+			Message:            err.Error(),
+		})
+		return err
+	}
+
+	log.FromContext(ctx).Info("Adopting existing KServe install", "deployment", key)
+	patch := client.MergeFrom(deployment.DeepCopy())
+	if deployment.Labels == nil {
+		deployment.Labels = map[string]string{}
+	}
+	deployment.Labels[managedByLabel] = managedByValue
+	deployment.Labels[ownerNameLabel] = kd.Name
+	deployment.Labels[ownerNamespaceLabel] = kd.Namespace
+	if err := r.Patch(ctx, deployment, patch); err != nil {
+		return fmt.Errorf("failed to adopt existing KServe install: %w", err)
+	}
+
+	meta.RemoveStatusCondition(&kd.Status.Conditions, "UnmanagedInstallDetected")
+	r.recordEvent(kd, corev1.EventTypeNormal, "AdoptedExistingInstall",
+		fmt.Sprintf("Adopted the existing KServe install in %s", key.Namespace))
+	return nil
+}
+
+// managedByOperator reports whether the operator applied obj. Resources
+// applied before the managed-by label was introduced carry the content hash.
+func managedByOperator(obj client.Object) bool {
+	return obj.GetLabels()[managedByLabel] == managedByValue || obj.GetAnnotations()[contentHashAnnotation] != ""
+}
@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// extraManifestPrefix marks extra manifests in InstalledComponents
+const extraManifestPrefix = "manifest/"
+
+// deployExtraManifests applies each extra manifest, records the resources it
+// created in status and prunes resources of manifests that were removed or
+// no longer contain them. It returns the InstalledComponents entries for the
+// manifests that were applied.
+func (r *KServeDeploymentReconciler) deployExtraManifests(ctx context.Context, kd *platformv1alpha1.KServeDeployment) ([]string, error) {
+	logger := log.FromContext(ctx)
+
+	installed := []string{}
+	statuses := append([]platformv1alpha1.ManifestStatus{}, kd.Status.ExtraManifests...)
+	defer func() { kd.Status.ExtraManifests = statuses }()
+
+	// KServe's webhook is probed once, before the first manifest that needs it
+	webhook := kserveWebhookGate{}
+	defer func() { webhook.setCondition(kd) }()
+
+	for _, ref := range kd.Spec.ExtraManifests {
+		manifestCtx := withComponentLogger(ctx, kd, extraManifestPrefix+ref.Name)
+		log.FromContext(manifestCtx).Info("Applying extra manifest", "manifest", ref.Name)
+
+		manifestBytes, err := r.readManifestRef(manifestCtx, kd, ref)
+		if err != nil {
+			return installed, withReason(platformv1alpha1.ReasonManifestFetchFailed, fmt.Errorf("extra manifest %s: %w", ref.Name, err))
+		}
+
+		if err := r.awaitKServeWebhook(manifestCtx, kd, &webhook, manifestBytes); err != nil {
+			return installed, err
+		}
+		if err := r.checkDefaultRuntimeFor(manifestCtx, kd, manifestBytes); err != nil {
+			return installed, fmt.Errorf("extra manifest %s: %w", ref.Name, err)
+		}
+
+		failedBefore := failedCount(manifestCtx)
+		applied, err := r.applyManifests(manifestCtx, manifestBytes, applyOptionsFor(kd))
+		if err != nil {
+			return installed, fmt.Errorf("extra manifest %s: %w", ref.Name, err)
+		}
+
+		// Prune resources the manifest no longer contains. Resources that are
+		// still in it but failed to apply are kept, and stay recorded.
+		resources := applied
+		if previous, ok := findManifestStatus(statuses, ref.Name); ok {
+			failed := failedSince(manifestCtx, failedBefore)
+			for _, missing := range missingResources(previous.Resources, applied) {
+				if failed[missing] {
+					resources = append(resources, missing)
+				}
+			}
+			r.deleteResources(manifestCtx, kd, missingResources(previous.Resources, resources))
+		}
+
+		statuses = setManifestStatus(statuses, platformv1alpha1.ManifestStatus{Name: ref.Name, Resources: resources})
+		installed = append(installed, extraManifestPrefix+ref.Name)
+	}
+
+	// Prune manifests that were removed from the spec
+	kept := []platformv1alpha1.ManifestStatus{}
+	for _, status := range statuses {
+		if hasExtraManifest(kd, status.Name) {
+			kept = append(kept, status)
+			continue
+		}
+		logger.Info("Pruning removed extra manifest", "manifest", status.Name)
+		r.deleteResources(ctx, kd, status.Resources)
+	}
+	statuses = kept
+
+	return installed, nil
+}
+
+// readManifestRef loads the manifest a ManifestRef points at
+func (r *KServeDeploymentReconciler) readManifestRef(ctx context.Context, kd *platformv1alpha1.KServeDeployment, ref platformv1alpha1.ManifestRef) ([]byte, error) {
+	switch {
+	case ref.URL != "" && ref.ConfigMapRef != nil:
+		return nil, fmt.Errorf("only one of url or configMapRef may be set")
+	case ref.Checksum != "" && ref.URL == "":
+		return nil, fmt.Errorf("checksum is only supported with url")
+	case ref.URL != "":
+		return r.fetchManifest(ctx, ref.URL, ref.Checksum)
+	case ref.ConfigMapRef != nil:
+		return r.readManifestConfigMap(ctx, kd, ref.ConfigMapRef)
+	default:
+		return nil, fmt.Errorf("one of url or configMapRef must be set")
+	}
+}
+
+// readManifestConfigMap reads the manifest stored under a ConfigMap key
+func (r *KServeDeploymentReconciler) readManifestConfigMap(ctx context.Context, kd *platformv1alpha1.KServeDeployment, ref *platformv1alpha1.ConfigMapKeyReference) ([]byte, error) {
+	if ref.Namespace != "" && ref.Namespace != kd.Namespace {
+		return nil, fmt.Errorf("ConfigMap %s/%s is not in the KServeDeployment's namespace %s", ref.Namespace, ref.Name, kd.Namespace)
+	}
+	key := configMapRefKey(kd, ref)
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, key, cm); err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s: %w", key, err)
+	}
+
+	if data, ok := cm.Data[ref.Key]; ok {
+		return []byte(data), nil
+	}
+	if data, ok := cm.BinaryData[ref.Key]; ok {
+		return data, nil
+	}
+	return nil, fmt.Errorf("ConfigMap %s has no key %q", key, ref.Key)
+}
+
+// configMapRefKey resolves a ConfigMap reference, which is always in the
+// KServeDeployment's namespace
+func configMapRefKey(kd *platformv1alpha1.KServeDeployment, ref *platformv1alpha1.ConfigMapKeyReference) client.ObjectKey {
+	return client.ObjectKey{Namespace: kd.Namespace, Name: ref.Name}
+}
+
+// requestsForConfigMap maps a ConfigMap to the KServeDeployments whose extra
+// manifests are read from it, looked up through the reference index, or to
+// every KServeDeployment for the pause ConfigMap
+func (r *KServeDeploymentReconciler) requestsForConfigMap(ctx context.Context, obj client.Object) []reconcile.Request {
+	changed := client.ObjectKeyFromObject(obj)
+	if !r.isPauseConfigMap(changed) {
+		return r.requestsForIndex(ctx, configMapRefIndex, changed)
+	}
+
+	list := &platformv1alpha1.KServeDeploymentList{}
+	if err := r.List(ctx, list); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list KServeDeployments for ConfigMap", "configmap", changed)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for i := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+	}
+	return requests
+}
+
+func hasExtraManifest(kd *platformv1alpha1.KServeDeployment, name string) bool {
+	for _, ref := range kd.Spec.ExtraManifests {
+		if ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func findManifestStatus(statuses []platformv1alpha1.ManifestStatus, name string) (platformv1alpha1.ManifestStatus, bool) {
+	for _, s := range statuses {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return platformv1alpha1.ManifestStatus{}, false
+}
+
+func setManifestStatus(statuses []platformv1alpha1.ManifestStatus, status platformv1alpha1.ManifestStatus) []platformv1alpha1.ManifestStatus {
+	for i := range statuses {
+		if statuses[i].Name == status.Name {
+			statuses[i] = status
+			return statuses
+		}
+	}
+	return append(statuses, status)
+}
+
+// missingResources returns the refs in previous that are not in current
+func missingResources(previous, current []platformv1alpha1.ResourceRef) []platformv1alpha1.ResourceRef {
+	keep := map[platformv1alpha1.ResourceRef]bool{}
+	for _, ref := range current {
+		keep[ref] = true
+	}
+
+	missing := []platformv1alpha1.ResourceRef{}
+	for _, ref := range previous {
+		if !keep[ref] {
+			missing = append(missing, ref)
+		}
+	}
+	return missing
+}
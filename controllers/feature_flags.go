@@ -0,0 +1,285 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// inferenceServiceConfig is KServe's main configuration ConfigMap
+const inferenceServiceConfig = "inferenceservice-config"
+
+// featureFlagDefaultsAnnotation records on inferenceservice-config the value
+// each requested flag's field had before the operator first set it; null
+// when the field wasn't there
+const featureFlagDefaultsAnnotation = "platform.ai-platform.io/feature-flag-defaults"
+
+// featureFlagSetting locates a feature flag inside inferenceservice-config
+type featureFlagSetting struct {
+	// key of the ConfigMap holding the JSON section
+	key string
+
+	// field inside the section
+	field string
+
+	// invert is set when the field disables the feature
+	invert bool
+
+	// quoted is set when KServe expects "true"/"false" strings
+	quoted bool
+}
+
+// featureFlagSettings maps each of platformv1alpha1.KnownFeatureFlags to its setting
+var featureFlagSettings = map[string]featureFlagSetting{
+	"directPvcVolumeMount": {key: "storageInitializer", field: "enableDirectPvcVolumeMount"},
+	"ingressCreation":      {key: "ingress", field: "disableIngressCreation", invert: true},
+	"istioVirtualHost":     {key: "ingress", field: "disableIstioVirtualHost", invert: true},
+	"metricAggregation":    {key: "metricsAggregator", field: "enableMetricAggregation", quoted: true},
+	"prometheusScraping":   {key: "metricsAggregator", field: "enablePrometheusScraping", quoted: true},
+}
+
+// applyFeatureFlags writes the requested feature flags into the
+// inferenceservice-config ConfigMap, leaving everything else in it as is. A
+// flag dropped from the spec gets back the value it had before it was set.
+func (r *KServeDeploymentReconciler) applyFeatureFlags(ctx context.Context, kd *platformv1alpha1.KServeDeployment) error {
+	// Rendering stops short of the API server; render patches the rendered
+	// ConfigMap instead, in renderFeatureFlags
+	if state := reconcileStateFrom(ctx); state != nil && state.render {
+		return nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: componentNamespace(kd, "kserve"), Name: inferenceServiceConfig}
+	if err := r.Get(ctx, key, cm); err != nil {
+		if errors.IsNotFound(err) && len(kd.Spec.FeatureFlags) == 0 {
+			return nil
+		}
+		return fmt.Errorf("failed to get %s: %w", key, err)
+	}
+
+	patch := client.MergeFrom(cm.DeepCopy())
+	changed, err := updateFeatureFlags(cm, kd.Spec.FeatureFlags)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	log.FromContext(ctx).Info("Updating KServe feature flags", "configmap", key)
+	if err := r.Patch(ctx, cm, patch); err != nil {
+		return fmt.Errorf("failed to update feature flags in %s: %w", key, err)
+	}
+	return nil
+}
+
+// updateFeatureFlags sets flags in the live ConfigMap. The value each flag's
+// field had before it was first set is kept in featureFlagDefaultsAnnotation,
+// and put back once the flag is no longer requested.
+func updateFeatureFlags(cm *corev1.ConfigMap, flags map[string]bool) (bool, error) {
+	defaults := map[string]interface{}{}
+	if raw := cm.Annotations[featureFlagDefaultsAnnotation]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &defaults); err != nil {
+			return false, fmt.Errorf("failed to parse %s on %s: %w", featureFlagDefaultsAnnotation, inferenceServiceConfig, err)
+		}
+	}
+
+	sections := newFeatureFlagSections(cm)
+	for _, name := range sortedKeys(defaults) {
+		setting, ok := featureFlagSettings[name]
+		if _, requested := flags[name]; requested || !ok {
+			continue
+		}
+		section, err := sections.get(setting.key)
+		if err != nil {
+			return false, err
+		}
+		sections.set(section, setting.key, setting.field, defaults[name])
+		delete(defaults, name)
+	}
+	for name := range flags {
+		setting, ok := featureFlagSettings[name]
+		if _, recorded := defaults[name]; recorded || !ok {
+			continue
+		}
+		section, err := sections.get(setting.key)
+		if err != nil {
+			return false, err
+		}
+		defaults[name] = section[setting.field]
+	}
+
+	if err := sections.setFlags(flags); err != nil {
+		return false, err
+	}
+	changed, err := sections.write()
+	if err != nil {
+		return false, err
+	}
+
+	annotation := ""
+	if len(defaults) > 0 {
+		data, err := json.Marshal(defaults)
+		if err != nil {
+			return false, err
+		}
+		annotation = string(data)
+	}
+	if annotation != cm.Annotations[featureFlagDefaultsAnnotation] {
+		if annotation == "" {
+			delete(cm.Annotations, featureFlagDefaultsAnnotation)
+		} else {
+			if cm.Annotations == nil {
+				cm.Annotations = map[string]string{}
+			}
+			cm.Annotations[featureFlagDefaultsAnnotation] = annotation
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
+// renderFeatureFlags writes the requested feature flags into the rendered
+// inferenceservice-config ConfigMap among objects, so the rendered manifests
+// show the configuration applyFeatureFlags leaves on the cluster
+func renderFeatureFlags(kd *platformv1alpha1.KServeDeployment, objects []unstructured.Unstructured) error {
+	if len(kd.Spec.FeatureFlags) == 0 {
+		return nil
+	}
+
+	namespace := componentNamespace(kd, "kserve")
+	for i := range objects {
+		obj := &objects[i]
+		if obj.GetKind() != "ConfigMap" || obj.GetNamespace() != namespace || obj.GetName() != inferenceServiceConfig {
+			continue
+		}
+
+		cm := &corev1.ConfigMap{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, cm); err != nil {
+			return fmt.Errorf("failed to read rendered %s: %w", inferenceServiceConfig, err)
+		}
+		changed, err := setFeatureFlags(cm, kd.Spec.FeatureFlags)
+		if err != nil || !changed {
+			return err
+		}
+		if err := unstructured.SetNestedStringMap(obj.Object, cm.Data, "data"); err != nil {
+			return err
+		}
+		// The content hash covers the data, so it is stamped again
+		return stampRendered(obj, takeKeepExisting(obj))
+	}
+	return nil
+}
+
+// setFeatureFlags sets each flag in the ConfigMap's JSON sections, reporting
+// whether anything changed. Sections that don't change keep their formatting.
+func setFeatureFlags(cm *corev1.ConfigMap, flags map[string]bool) (bool, error) {
+	sections := newFeatureFlagSections(cm)
+	if err := sections.setFlags(flags); err != nil {
+		return false, err
+	}
+	return sections.write()
+}
+
+// featureFlagSections edits the JSON sections of an inferenceservice-config
+// ConfigMap, parsing each on first use and writing back only those changed
+type featureFlagSections struct {
+	cm       *corev1.ConfigMap
+	sections map[string]map[string]interface{}
+	dirty    map[string]bool
+}
+
+func newFeatureFlagSections(cm *corev1.ConfigMap) *featureFlagSections {
+	return &featureFlagSections{cm: cm, sections: map[string]map[string]interface{}{}, dirty: map[string]bool{}}
+}
+
+// get returns the parsed section under key
+func (s *featureFlagSections) get(key string) (map[string]interface{}, error) {
+	if section, ok := s.sections[key]; ok {
+		return section, nil
+	}
+	section := map[string]interface{}{}
+	if raw := s.cm.Data[key]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &section); err != nil {
+			return nil, fmt.Errorf("failed to parse %s in %s: %w", key, inferenceServiceConfig, err)
+		}
+	}
+	s.sections[key] = section
+	return section, nil
+}
+
+// set sets field in section, removing it when value is nil
+func (s *featureFlagSections) set(section map[string]interface{}, key, field string, value interface{}) {
+	current, present := section[field]
+	if value == nil {
+		if present {
+			delete(section, field)
+			s.dirty[key] = true
+		}
+		return
+	}
+	if !present || !reflect.DeepEqual(current, value) {
+		section[field] = value
+		s.dirty[key] = true
+	}
+}
+
+// setFlags sets the field of each flag to the flag's value
+func (s *featureFlagSections) setFlags(flags map[string]bool) error {
+	for _, name := range sortedKeys(flags) {
+		setting, ok := featureFlagSettings[name]
+		if !ok {
+			return fmt.Errorf("unknown feature flag %q", name)
+		}
+		section, err := s.get(setting.key)
+		if err != nil {
+			return err
+		}
+
+		enabled := flags[name] != setting.invert
+		var value interface{} = enabled
+		if setting.quoted {
+			value = strconv.FormatBool(enabled)
+		}
+		s.set(section, setting.key, setting.field, value)
+	}
+	return nil
+}
+
+// write stores the changed sections in the ConfigMap, reporting whether there
+// were any
+func (s *featureFlagSections) write() (bool, error) {
+	for key := range s.dirty {
+		data, err := json.MarshalIndent(s.sections[key], "", "  ")
+		if err != nil {
+			return false, err
+		}
+		if s.cm.Data == nil {
+			s.cm.Data = map[string]string{}
+		}
+		s.cm.Data[key] = string(data)
+	}
+	return len(s.dirty) > 0, nil
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+const featureFlagManifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: inferenceservice-config
+  namespace: kserve
+data:
+  ingress: '{"ingressGateway": "knative-serving/knative-ingress-gateway"}'
+`
+
+func TestRenderedManifestsCarryFeatureFlags(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	kd := &platformv1alpha1.KServeDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "kserve-minimal", Namespace: "default"},
+		Spec: platformv1alpha1.KServeDeploymentSpec{
+			Namespace:    "kserve",
+			Version:      "v0.11.0",
+			PinManifests: true,
+			FeatureFlags: map[string]bool{"ingressCreation": false},
+		},
+		Status: platformv1alpha1.KServeDeploymentStatus{
+			ManifestSnapshot: &platformv1alpha1.ManifestSnapshotStatus{Version: "v0.11.0"},
+		},
+	}
+	snapshot := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: manifestSnapshotName(kd), Namespace: kd.Namespace},
+		Data:       map[string]string{renderedManifestsKey: featureFlagManifest},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(snapshot).Build()
+	r := &KServeDeploymentReconciler{Client: c}
+
+	rendered, err := r.renderObjects(context.Background(), kd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rendered) != 1 {
+		t.Fatalf("rendered %d objects, want 1", len(rendered))
+	}
+	obj := rendered[0]
+
+	raw := obj.Object["data"].(map[string]interface{})["ingress"].(string)
+	ingress := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(raw), &ingress); err != nil {
+		t.Fatal(err)
+	}
+	if ingress["disableIngressCreation"] != true {
+		t.Errorf("rendered ingress section = %s, want disableIngressCreation true", raw)
+	}
+	if ingress["ingressGateway"] != "knative-serving/knative-ingress-gateway" {
+		t.Errorf("rendered ingress section lost ingressGateway: %s", raw)
+	}
+
+	// The content hash covers the patched data
+	hash, err := contentHash(&obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.GetAnnotations()[contentHashAnnotation] != hash {
+		t.Errorf("rendered %s annotation = %q, want %q", contentHashAnnotation, obj.GetAnnotations()[contentHashAnnotation], hash)
+	}
+}
+
+func TestRemovedFeatureFlagsAreRestored(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	key := client.ObjectKey{Namespace: "kserve", Name: inferenceServiceConfig}
+	live := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		Data: map[string]string{
+			"ingress":           `{"disableIngressCreation": false, "ingressGateway": "knative-serving/knative-ingress-gateway"}`,
+			"metricsAggregator": `{"enableMetricAggregation": "false"}`,
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(live).Build()
+	r := &KServeDeploymentReconciler{Client: c}
+	kd := &platformv1alpha1.KServeDeployment{Spec: platformv1alpha1.KServeDeploymentSpec{
+		Namespace:    "kserve",
+		FeatureFlags: map[string]bool{"ingressCreation": false, "prometheusScraping": true},
+	}}
+	ctx := context.Background()
+
+	section := func(name string) map[string]interface{} {
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(ctx, key, cm); err != nil {
+			t.Fatal(err)
+		}
+		parsed := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(cm.Data[name]), &parsed); err != nil {
+			t.Fatal(err)
+		}
+		return parsed
+	}
+
+	if err := r.applyFeatureFlags(ctx, kd); err != nil {
+		t.Fatal(err)
+	}
+	if section("ingress")["disableIngressCreation"] != true || section("metricsAggregator")["enablePrometheusScraping"] != "true" {
+		t.Fatalf("flags weren't applied: ingress %v, metricsAggregator %v", section("ingress"), section("metricsAggregator"))
+	}
+
+	// Dropping the flags puts back what the manifest had
+	kd.Spec.FeatureFlags = nil
+	if err := r.applyFeatureFlags(ctx, kd); err != nil {
+		t.Fatal(err)
+	}
+	if got := section("ingress")["disableIngressCreation"]; got != false {
+		t.Errorf("disableIngressCreation = %v, want the manifest's false", got)
+	}
+	if got, ok := section("metricsAggregator")["enablePrometheusScraping"]; ok {
+		t.Errorf("enablePrometheusScraping = %v, want it removed as in the manifest", got)
+	}
+	if got := section("ingress")["ingressGateway"]; got != "knative-serving/knative-ingress-gateway" {
+		t.Errorf("ingressGateway = %v, want it left alone", got)
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, key, cm); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cm.Annotations[featureFlagDefaultsAnnotation]; ok {
+		t.Errorf("%s left behind with no flags set", featureFlagDefaultsAnnotation)
+	}
+}
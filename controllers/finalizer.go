@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// cleanupFinalizer makes deleting a KServeDeployment delete the resources it
+// applied, as recorded in Status.ManagedResources
+const cleanupFinalizer = "platform.ai-platform.io/cleanup"
+
+// ensureFinalizer adds the cleanup finalizer if it is missing
+func (r *KServeDeploymentReconciler) ensureFinalizer(ctx context.Context, kd *platformv1alpha1.KServeDeployment) error {
+	if controllerutil.ContainsFinalizer(kd, cleanupFinalizer) {
+		return nil
+	}
+
+	controllerutil.AddFinalizer(kd, cleanupFinalizer)
+	if err := r.Update(ctx, kd); err != nil {
+		return fmt.Errorf("failed to add finalizer: %w", err)
+	}
+	return nil
+}
+
+// finalize deletes every managed resource no other KServeDeployment also
+// manages, newest first, and with DeleteNamespaceOnCleanup the namespaces the
+// operator created, then releases the object. CRDs are kept unless
+// DeleteCRDsOnCleanup is set. Runtime
+// verification probes still running are deleted too. With a
+// DeletionGracePeriod the teardown waits that long after the deletion was
+// requested.
+func (r *KServeDeploymentReconciler) finalize(ctx context.Context, kd *platformv1alpha1.KServeDeployment) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(kd, cleanupFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	// Leave the install serving until the grace period is over
+	if kd.Spec.DeletionGracePeriod != nil {
+		drainUntil := kd.DeletionTimestamp.Add(kd.Spec.DeletionGracePeriod.Duration)
+		if remaining := time.Until(drainUntil); remaining > 0 {
+			return r.awaitDeletionGracePeriod(ctx, kd, drainUntil, remaining)
+		}
+	}
+
+	log.FromContext(ctx).Info("Deleting managed resources", "count", len(kd.Status.ManagedResources))
+	r.deleteResources(ctx, kd, reverseResourceRefs(kd.Status.ManagedResources))
+	r.deleteCreatedNamespaces(ctx, kd)
+	r.deleteRuntimeProbes(ctx, kd, probeNamespaces(kd, nil), nil)
+
+	controllerutil.RemoveFinalizer(kd, cleanupFinalizer)
+	if err := r.Update(ctx, kd); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to remove finalizer: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// awaitDeletionGracePeriod reports the deployment Terminating and requeues
+// for the end of the grace period
+func (r *KServeDeploymentReconciler) awaitDeletionGracePeriod(ctx context.Context, kd *platformv1alpha1.KServeDeployment, drainUntil time.Time, remaining time.Duration) (ctrl.Result, error) {
+	if kd.Status.Phase != "Terminating" {
+		log.FromContext(ctx).Info("Waiting for the deletion grace period before removing resources", "until", drainUntil)
+		message := fmt.Sprintf("KServe deployment is terminating: resources are removed at %s", drainUntil.UTC().Format(time.RFC3339))
+		result, err := r.updateStatusWithMessage(ctx, kd, "Terminating", kd.Status.InstalledVersion, kd.Status.InstalledComponents, message)
+		if err != nil || result.Requeue {
+			return result, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: remaining}, nil
+}
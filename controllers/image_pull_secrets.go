@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// podSpecPaths locates the pod spec inside each kind of workload
+var podSpecPaths = map[string][]string{
+	"Deployment":  {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"ReplicaSet":  {"spec", "template", "spec"},
+	"Job":         {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// imagePullFailureReasons are the container waiting reasons of a failed pull
+var imagePullFailureReasons = map[string]bool{
+	"ErrImagePull":     true,
+	"ImagePullBackOff": true,
+}
+
+// injectImagePullSecrets returns a mutator that adds the named pull secrets
+// to ServiceAccounts and to the pod spec of workloads
+func injectImagePullSecrets(names []string) objectMutator {
+	return func(obj *unstructured.Unstructured) error {
+		path := []string{"imagePullSecrets"}
+		if obj.GetKind() != "ServiceAccount" {
+			podSpec, ok := podSpecPaths[obj.GetKind()]
+			if !ok {
+				return nil
+			}
+			path = append(append([]string{}, podSpec...), "imagePullSecrets")
+		}
+
+		existing, _, err := unstructured.NestedSlice(obj.Object, path...)
+		if err != nil {
+			return fmt.Errorf("failed to read imagePullSecrets: %w", err)
+		}
+
+		present := map[string]bool{}
+		for _, ref := range existing {
+			if m, ok := ref.(map[string]interface{}); ok {
+				if name, ok := m["name"].(string); ok {
+					present[name] = true
+				}
+			}
+		}
+		for _, name := range names {
+			if !present[name] {
+				existing = append(existing, map[string]interface{}{"name": name})
+			}
+		}
+
+		return unstructured.SetNestedSlice(obj.Object, existing, path...)
+	}
+}
+
+// ensureImagePullSecrets makes every pull secret available in each component
+// namespace, copying it from the KServeDeployment's namespace when missing
+func (r *KServeDeploymentReconciler) ensureImagePullSecrets(ctx context.Context, kd *platformv1alpha1.KServeDeployment) error {
+	for _, name := range kd.Spec.ImagePullSecrets {
+		for _, ns := range requiredNamespaces(kd) {
+			if ns == kd.Namespace {
+				continue
+			}
+			if err := r.copyImagePullSecret(ctx, kd, name, ns); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// copyImagePullSecret copies a pull secret into namespace, and keeps a copy
+// made by the operator in sync with its source. Secrets the operator didn't
+// create are left alone.
+func (r *KServeDeploymentReconciler) copyImagePullSecret(ctx context.Context, kd *platformv1alpha1.KServeDeployment, name, namespace string) error {
+	logger := log.FromContext(ctx)
+
+	existing := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to get image pull secret %s/%s: %w", namespace, name, err)
+	}
+	copied := err == nil
+	if copied && !ownedBy(existing.Labels, kd) {
+		return nil
+	}
+
+	source := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: kd.Namespace, Name: name}, source); err != nil {
+		if errors.IsNotFound(err) {
+			if copied {
+				// Keep pulling with the last known credentials
+				return nil
+			}
+			return fmt.Errorf("image pull secret %s exists in neither %s nor %s", name, namespace, kd.Namespace)
+		}
+		return fmt.Errorf("failed to get image pull secret %s/%s: %w", kd.Namespace, name, err)
+	}
+
+	if copied {
+		if reflect.DeepEqual(existing.Data, source.Data) && existing.Labels[watchLabel] == "true" {
+			return nil
+		}
+		logger.Info("Refreshing image pull secret copy", "secret", name, "from", kd.Namespace, "to", namespace)
+		existing.Data = source.Data
+		// Copies made before the Secret informer was restricted lack the label
+		existing.Labels[watchLabel] = "true"
+		if err := r.Update(ctx, existing); err != nil {
+			return fmt.Errorf("failed to refresh image pull secret %s in %s: %w", name, namespace, err)
+		}
+		return nil
+	}
+
+	logger.Info("Copying image pull secret", "secret", name, "from", kd.Namespace, "to", namespace)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				ownerNameLabel:      kd.Name,
+				ownerNamespaceLabel: kd.Namespace,
+				watchLabel:          "true",
+			},
+		},
+		Type: source.Type,
+		Data: source.Data,
+	}
+	if err := r.Create(ctx, secret); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to copy image pull secret %s to %s: %w", name, namespace, err)
+	}
+	return nil
+}
+
+// ownedBy reports whether labels mark an object as created for kd
+func ownedBy(labels map[string]string, kd *platformv1alpha1.KServeDeployment) bool {
+	return labels[ownerNameLabel] == kd.Name && labels[ownerNamespaceLabel] == kd.Namespace
+}
+
+// setImagePullCondition reports pods in the component namespaces that can't
+// pull their images, so a broken pull secret doesn't fail silently
+func (r *KServeDeploymentReconciler) setImagePullCondition(ctx context.Context, kd *platformv1alpha1.KServeDeployment) {
+	failures := []string{}
+	for _, ns := range requiredNamespaces(kd) {
+		pods := &corev1.PodList{}
+		if err := r.List(ctx, pods, client.InNamespace(ns)); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to list pods", "namespace", ns)
+			continue
+		}
+		failures = append(failures, imagePullFailures(pods.Items)...)
+	}
+
+	if len(failures) == 0 {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "ImagePullFailed")
+		return
+	}
+
+	sort.Strings(failures)
+	meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+		Type:               "ImagePullFailed",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kd.Generation,
+		Reason:             platformv1alpha1.ReasonImagePullBackOff,
+		Message:            strings.Join(failures, "; "),
+	})
+}
+
+// imagePullFailures describes each container stuck pulling its image
+func imagePullFailures(pods []corev1.Pod) []string {
+	failures := []string{}
+	for _, pod := range pods {
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, cs := range statuses {
+			if cs.State.Waiting != nil && imagePullFailureReasons[cs.State.Waiting.Reason] {
+				failures = append(failures, fmt.Sprintf("%s/%s: %s (%s)", pod.Namespace, pod.Name, cs.Image, cs.State.Waiting.Reason))
+			}
+		}
+	}
+	return failures
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// Deployment metadata stamped on applied InferenceServices. The operator's
+// prefix keeps them clear of the serving.kserve.io annotations KServe manages.
+const (
+	// ownerAnnotation is the namespace/name of the owning KServeDeployment
+	ownerAnnotation = "platform.ai-platform.io/owner"
+
+	// kserveVersionAnnotation is the KServe version the object was applied with
+	kserveVersionAnnotation = "platform.ai-platform.io/kserve-version"
+
+	// appliedAtAnnotation is when the operator last wrote the object. It is
+	// left out of the content hash, so it only moves when the object changes.
+	appliedAtAnnotation = "platform.ai-platform.io/applied-at"
+)
+
+// annotateInferenceServices returns a mutator that stamps InferenceServices
+// with the owning KServeDeployment, its KServe version and now
+func annotateInferenceServices(kd *platformv1alpha1.KServeDeployment, now time.Time) objectMutator {
+	return func(obj *unstructured.Unstructured) error {
+		if !isInferenceService(obj) {
+			return nil
+		}
+		setAnnotation(obj, ownerAnnotation, kd.Namespace+"/"+kd.Name)
+		setAnnotation(obj, kserveVersionAnnotation, kd.Spec.Version)
+		setAnnotation(obj, appliedAtAnnotation, now.UTC().Format(time.RFC3339))
+		return nil
+	}
+}
+
+// recordInferenceServices lists the applied InferenceServices in status with
+// the metadata stamped on the live objects
+func (r *KServeDeploymentReconciler) recordInferenceServices(ctx context.Context, kd *platformv1alpha1.KServeDeployment, applied []platformv1alpha1.ResourceRef) {
+	if state := reconcileStateFrom(ctx); state != nil && state.render {
+		return
+	}
+
+	statuses := []platformv1alpha1.InferenceServiceStatus{}
+	for _, ref := range applied {
+		live := &unstructured.Unstructured{}
+		live.SetAPIVersion(ref.APIVersion)
+		live.SetKind(ref.Kind)
+		if !isInferenceService(live) {
+			continue
+		}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, live); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to get InferenceService", "name", ref.Name, "namespace", ref.Namespace)
+			continue
+		}
+
+		annotations := live.GetAnnotations()
+		status := platformv1alpha1.InferenceServiceStatus{
+			Name:          ref.Name,
+			Namespace:     ref.Namespace,
+			KServeVersion: annotations[kserveVersionAnnotation],
+		}
+		if appliedAt, err := time.Parse(time.RFC3339, annotations[appliedAtAnnotation]); err == nil {
+			status.AppliedAt = metav1.NewTime(appliedAt)
+		}
+		statuses = append(statuses, status)
+	}
+	kd.Status.InferenceServices = statuses
+}
+
+func isInferenceService(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return gvk.Group == "serving.kserve.io" && gvk.Kind == "InferenceService"
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// inferenceServicePollInterval is how often a deployment checks on
+// InferenceServices that aren't Ready yet
+const inferenceServicePollInterval = 30 * time.Second
+
+// gateOnInferenceServices reports the readiness of the InferenceServices in
+// status.inferenceServices in the InferenceServicesReady condition. Under the
+// Strict policy a Ready phase is held back until all of them are Ready: it
+// becomes Degraded when a model failed to load and stays Installing
+// otherwise. It returns the phase to report, the Ready reason and a status
+// message when the phase was held back, and whether to check the
+// InferenceServices again.
+func (r *KServeDeploymentReconciler) gateOnInferenceServices(ctx context.Context, kd *platformv1alpha1.KServeDeployment, phase string) (string, string, string, bool) {
+	if len(kd.Status.InferenceServices) == 0 {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "InferenceServicesReady")
+		return phase, "", "", false
+	}
+
+	notReady, failed := []string{}, []string{}
+	for _, status := range kd.Status.InferenceServices {
+		key := client.ObjectKey{Namespace: status.Namespace, Name: status.Name}
+		isvc := &unstructured.Unstructured{}
+		isvc.SetAPIVersion("serving.kserve.io/v1beta1")
+		isvc.SetKind("InferenceService")
+		if err := r.Get(ctx, key, isvc); err != nil {
+			reason := "not found"
+			if !errors.IsNotFound(err) {
+				log.FromContext(ctx).Error(err, "Failed to get InferenceService", "name", status.Name, "namespace", status.Namespace)
+				reason = err.Error()
+			}
+			notReady = append(notReady, fmt.Sprintf("%s: %s", key, reason))
+			continue
+		}
+
+		if ready, reason := inferenceServiceReady(isvc); !ready {
+			notReady = append(notReady, fmt.Sprintf("%s: %s", key, reason))
+			if modelFailed(isvc) {
+				failed = append(failed, key.String())
+			}
+		}
+	}
+
+	if len(notReady) == 0 {
+		meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+			Type:               "InferenceServicesReady",
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: kd.Generation,
+			Reason:             platformv1alpha1.ReasonAllReady,
+			Message:            fmt.Sprintf("%d of %d InferenceServices are Ready", len(kd.Status.InferenceServices), len(kd.Status.InferenceServices)),
+		})
+		return phase, "", "", false
+	}
+
+	reason := platformv1alpha1.ReasonNotReady
+	if len(failed) > 0 {
+		reason = platformv1alpha1.ReasonModelLoadFailed
+	}
+	meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+		Type:               "InferenceServicesReady",
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: kd.Generation,
+		Reason:             reason,
+		Message:            strings.Join(notReady, "; "),
+	})
+
+	if phase != "Ready" || kd.Spec.InferenceServiceReadiness == "BestEffort" {
+		return phase, "", "", true
+	}
+	if len(failed) > 0 {
+		return "Degraded", platformv1alpha1.ReasonModelLoadFailed, fmt.Sprintf("KServe deployment is degraded: InferenceServices %s failed to load their models",
+			strings.Join(failed, ", ")), true
+	}
+	return "Installing", platformv1alpha1.ReasonWaitingForReadiness, fmt.Sprintf("Waiting for InferenceServices to become Ready: %s", strings.Join(notReady, "; ")), true
+}
+
+// modelFailed reports whether KServe gave up loading an InferenceService's
+// model, as opposed to still rolling it out
+func modelFailed(isvc *unstructured.Unstructured) bool {
+	transition, _, _ := unstructured.NestedString(isvc.Object, "status", "modelStatus", "transitionStatus")
+	return transition == "BlockedByFailedLoad" || transition == "InvalidSpec"
+}
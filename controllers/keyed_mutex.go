@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// keyedMutex serializes work per object UID. controller-runtime already
+// reconciles each key one at a time, but the operator also holds state shared
+// across objects (the source circuit breaker), so the mutating part of a
+// reconcile takes this lock to stay safe if MaxConcurrentReconciles is raised
+// or the same object is reached through more than one key. The zero value is
+// ready to use.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[types.UID]*keyedLock
+}
+
+type keyedLock struct {
+	mu      sync.Mutex
+	waiters int
+}
+
+// Lock blocks until the lock for uid is held and returns the function that
+// releases it
+func (k *keyedMutex) Lock(uid types.UID) (unlock func()) {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = map[types.UID]*keyedLock{}
+	}
+	lock, ok := k.locks[uid]
+	if !ok {
+		lock = &keyedLock{}
+		k.locks[uid] = lock
+	}
+	lock.waiters++
+	k.mu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		// Drop the entry once nobody holds or waits on it so the map doesn't
+		// grow with every object ever reconciled
+		lock.waiters--
+		if lock.waiters == 0 {
+			delete(k.locks, uid)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+func TestKeyedMutexSerializesPerUID(t *testing.T) {
+	var locks keyedMutex
+	uids := []types.UID{"first", "second"}
+	counts := make([]int, len(uids))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		for j, uid := range uids {
+			wg.Add(1)
+			go func(j int, uid types.UID) {
+				defer wg.Done()
+				unlock := locks.Lock(uid)
+				defer unlock()
+				counts[j]++
+			}(j, uid)
+		}
+	}
+	wg.Wait()
+
+	for j, count := range counts {
+		if count != 50 {
+			t.Errorf("%s was incremented %d times, want 50", uids[j], count)
+		}
+	}
+	if len(locks.locks) != 0 {
+		t.Errorf("%d locks are left behind", len(locks.locks))
+	}
+}
+
+// TestConcurrentReconcilesShareStateSafely reconciles two objects from many
+// goroutines at once through the shared circuit breaker and manifest cache.
+// Run it with -race.
+func TestConcurrentReconcilesShareStateSafely(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := platformv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	// The fake client registers unknown list kinds on first use, which races
+	// with concurrent reads of the scheme; register the ones reconciles list
+	for _, gvk := range []schema.GroupVersionKind{
+		{Group: "serving.kserve.io", Version: "v1beta1", Kind: "InferenceService"},
+		servingRuntimeGVK,
+		clusterServingRuntimeGVK,
+	} {
+		scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+		scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprint(w, "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: extra\n")
+	}))
+	defer server.Close()
+
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithStatusSubresource(&platformv1alpha1.KServeDeployment{})
+	var keys []types.NamespacedName
+	for _, name := range []string{"first", "second"} {
+		kd := &platformv1alpha1.KServeDeployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "platform", UID: types.UID(name)}}
+		kd.Spec.Version = "v0.11.0"
+		kd.Spec.Namespace = "kserve"
+		kd.Spec.ExtraManifests = []platformv1alpha1.ManifestRef{{Name: "extra", URL: server.URL}}
+		builder = builder.WithObjects(kd)
+		keys = append(keys, types.NamespacedName{Namespace: kd.Namespace, Name: kd.Name})
+	}
+
+	cache, err := NewManifestCache(t.TempDir(), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &KServeDeploymentReconciler{
+		Client:        builder.Build(),
+		Scheme:        scheme,
+		SourceBreaker: NewCircuitBreaker(3, time.Minute),
+		ManifestCache: cache,
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		for _, key := range keys {
+			wg.Add(1)
+			go func(key types.NamespacedName) {
+				defer wg.Done()
+				// Conflicting status writes are expected; the race detector
+				// is what this test checks
+				_, _ = r.Reconcile(context.Background(), ctrl.Request{NamespacedName: key})
+			}(key)
+		}
+	}
+	wg.Wait()
+
+	if _, ok := cache.Get(server.URL, ""); !ok {
+		t.Error("the extra manifest was never fetched into the cache")
+	}
+}
@@ -1,30 +1,80 @@
 package controllers
 
 import (
-	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
+	"strings"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
 )
 
-// KServeDeploymentReconciler reconciles a KServeDeployment object
+// KServeDeploymentReconciler reconciles a KServeDeployment object. It never
+// writes the spec: everything it needs to remember between reconciles lives
+// in status, so GitOps tools that own the spec don't see it drift.
 type KServeDeploymentReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// SourceBreaker short-circuits fetches from repeatedly failing manifest sources
+	SourceBreaker *CircuitBreaker
+
+	// SourceLatency flags manifest fetches that are much slower than usual
+	SourceLatency *LatencyTracker
+
+	// RetryBudget caps apply retries across all objects
+	RetryBudget *RetryBudget
+
+	// ManifestCache keeps fetched manifests on disk between reconciles
+	ManifestCache *ManifestCache
+
+	// APIReader reads objects the cache doesn't hold, or must not serve stale
+	APIReader client.Reader
+
+	// WatchNamespace restricts the operator to a single namespace when set
+	WatchNamespace string
+
+	// OperatorVersion identifies the operator build in component statuses
+	OperatorVersion string
+
+	// StabilizationPeriod a Degraded deployment must stay healthy before it is
+	// reported Ready again
+	StabilizationPeriod time.Duration
+
+	// Recorder emits Events on KServeDeployment objects
+	Recorder record.EventRecorder
+
+	// CanaryPeriod is how long canary deployments must be Ready at a new
+	// version before the other deployments are upgraded to it
+	CanaryPeriod time.Duration
+
+	// PauseConfigMap is the operator-wide kill switch: while this ConfigMap
+	// exists no KServeDeployment is reconciled. Empty disables it.
+	PauseConfigMap types.NamespacedName
+
+	// locks serializes the mutating part of reconciles per object UID
+	locks keyedMutex
 }
 
 // +kubebuilder:rbac:groups=platform.ai-platform.io,resources=kservedeployments,verbs=get;list;watch;create;update;patch;delete
@@ -32,10 +82,29 @@ type KServeDeploymentReconciler struct {
 // +kubebuilder:rbac:groups=platform.ai-platform.io,resources=kservedeployments/finalizers,verbs=update
 // +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=secrets;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=core,resources=resourcequotas,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=serving.kserve.io,resources=inferenceservices,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=serving.kserve.io,resources=servingruntimes;clusterservingruntimes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+
+func (r *KServeDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	start := time.Now()
+	ctx = withReconcileState(ctx, start)
+	defer func() { observeReconcile(start, err) }()
+
+	ctx, span := startSpan(ctx, "Reconcile", attribute.String("kservedeployment", req.NamespacedName.String()))
+	defer func() {
+		span.SetAttributes(attribute.Bool("requeue", result.Requeue || result.RequeueAfter > 0))
+		endSpan(span, err)
+	}()
 
-func (r *KServeDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 
 	// Fetch the KServeDeployment instance
@@ -49,37 +118,387 @@ func (r *KServeDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, err
 	}
 
+	// Hold the per-object lock for everything that mutates the cluster, the
+	// status or shared operator state
+	unlock := r.locks.Lock(kserveDeployment.UID)
+	defer unlock()
+
+	// Stop everything while the operator-wide kill switch is set
+	paused, err := r.globallyPaused(ctx)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if paused {
+		logger.Info("Reconciles are globally paused", "configmap", r.PauseConfigMap)
+		return ctrl.Result{}, r.reportPaused(ctx, kserveDeployment)
+	}
+	meta.RemoveStatusCondition(&kserveDeployment.Status.Conditions, "GloballyPaused")
+
+	// Delete what was applied for a deleted object, then let it go
+	if !kserveDeployment.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, kserveDeployment)
+	}
+	if err := r.ensureFinalizer(ctx, kserveDeployment); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Deploy each component once even if it is listed twice. This only
+	// changes the in-memory copy; the spec is never written back.
+	kserveDeployment.Spec.Components = uniqueStrings(kserveDeployment.Spec.Components)
+
 	logger.Info("Reconciling KServeDeployment", "name", kserveDeployment.Name, "version", kserveDeployment.Spec.Version)
 
+	// Time the install from its first reconcile or latest spec change
+	startInstallTimer(kserveDeployment)
+
+	// Never let a spec the webhook would reject replace a working install
+	if err := checkSpec(kserveDeployment); err != nil {
+		return r.handleInvalidSpec(ctx, kserveDeployment, err)
+	}
+
+	// Change-controlled deployments wait for each spec to be approved
+	waiting, err := r.awaitingApproval(ctx, kserveDeployment)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if waiting {
+		logger.Info("Waiting for approval", "annotation", approveAnnotation)
+		return r.updateStatusWithMessage(ctx, kserveDeployment, "Pending", kserveDeployment.Status.InstalledVersion,
+			kserveDeployment.Status.InstalledComponents, "Waiting for approval of the current spec")
+	}
+
 	// Update status to Installing if not already set
 	if kserveDeployment.Status.Phase == "" {
-		if _, err := r.updateStatus(ctx, kserveDeployment, "Installing", "", nil); err != nil {
+		if _, err := r.updateStatus(ctx, kserveDeployment, "Installing", kserveDeployment.Status.InstalledVersion, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Compare KServe versions on request; this never applies anything
+	r.reconcileVersionDiff(ctx, kserveDeployment)
+
+	// Cluster-scoped components can't be installed in single-namespace mode
+	if err := r.checkScope(kserveDeployment); err != nil {
+		logger.Error(err, "Requested components are incompatible with the watch namespace")
+		return r.markFailed(ctx, kserveDeployment, nil, withReason(platformv1alpha1.ReasonScopeConflict, err))
+	}
+
+	// A scheduled reconcile re-applies everything, changed or not
+	if kserveDeployment.Spec.ReconcileSchedule != "" {
+		if _, err := parseCronSchedule(kserveDeployment.Spec.ReconcileSchedule); err != nil {
+			logger.Error(err, "Invalid reconcile schedule")
+			return r.markFailed(ctx, kserveDeployment, nil, withReason(platformv1alpha1.ReasonValidationFailed, fmt.Errorf("invalid reconcileSchedule: %w", err)))
+		}
+		if scheduledReconcileDue(kserveDeployment, time.Now()) {
+			logger.Info("Running scheduled reconcile, re-applying all resources")
+			if state := reconcileStateFrom(ctx); state != nil {
+				state.forceApply = true
+			}
+		}
+	}
+
+	// Leave the installed version alone rather than downgrade by accident
+	if err := checkDowngrade(kserveDeployment); err != nil {
+		logger.Info("Refusing to downgrade", "reason", err.Error())
+		return r.updateStatusWithReason(ctx, kserveDeployment, kserveDeployment.Status.Phase, platformv1alpha1.ReasonDowngradeNotAllowed,
+			kserveDeployment.Status.InstalledVersion, kserveDeployment.Status.InstalledComponents, err.Error())
+	}
+
+	// Let the canaries prove a new version before the rest of the fleet moves
+	canaryWait, holdMessage, err := r.checkCanaries(ctx, kserveDeployment)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if canaryWait > 0 {
+		logger.Info("Holding upgrade for canaries", "reason", holdMessage)
+		reason := platformv1alpha1.ReasonWaitingForCanaries
+		if hold := meta.FindStatusCondition(kserveDeployment.Status.Conditions, "CanaryHold"); hold != nil {
+			reason = hold.Reason
+		}
+		result, err := r.updateStatusWithReason(ctx, kserveDeployment, kserveDeployment.Status.Phase, reason,
+			kserveDeployment.Status.InstalledVersion, kserveDeployment.Status.InstalledComponents, holdMessage)
+		if err == nil && (result.RequeueAfter == 0 || canaryWait < result.RequeueAfter) {
+			result.RequeueAfter = canaryWait
+		}
+		return result, err
+	}
+
+	// Create the namespaces the requested components install into
+	recreated, err := r.ensureNamespaces(ctx, kserveDeployment)
+	recordCreatedNamespaces(kserveDeployment, recreated)
+	if err != nil {
+		if shuttingDown(ctx) {
+			return r.abandonReconcile(ctx)
+		}
+		if terminating, ok := asNamespaceTerminating(err); ok {
+			logger.Info("Waiting for namespace deletion to finish before recreating it", "namespace", terminating.Namespace)
+			return ctrl.Result{RequeueAfter: namespaceTerminatingPollInterval}, nil
+		}
+		logger.Error(err, "Failed to ensure component namespaces")
+		return r.updateStatus(ctx, kserveDeployment, "Failed", kserveDeployment.Status.InstalledVersion, nil)
+	}
+
+	// A namespace of an installed deployment went missing, taking its
+	// resources with it; reinstall rather than keep reporting Ready
+	if len(recreated) > 0 && kserveDeployment.Status.InstalledVersion != "" {
+		logger.Info("Component namespaces were deleted, reinstalling", "namespaces", recreated)
+		r.recordEvent(kserveDeployment, corev1.EventTypeWarning, "NamespaceRecreated",
+			fmt.Sprintf("Recreated deleted namespace(s) %s and re-applying components", strings.Join(recreated, ", ")))
+		if _, err := r.updateStatus(ctx, kserveDeployment, "Installing", kserveDeployment.Status.InstalledVersion, nil); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
+	// Make the image pull secrets available where the components run
+	if err := r.ensureImagePullSecrets(ctx, kserveDeployment); err != nil {
+		if shuttingDown(ctx) {
+			return r.abandonReconcile(ctx)
+		}
+		logger.Error(err, "Failed to ensure image pull secrets")
+		return r.markFailed(ctx, kserveDeployment, nil, withReason(platformv1alpha1.ReasonDependencyMissing, err))
+	}
+
+	// Give the InferenceServices' storage initializer its credentials
+	if err := r.ensureStorageCredentials(ctx, kserveDeployment); err != nil {
+		if shuttingDown(ctx) {
+			return r.abandonReconcile(ctx)
+		}
+		logger.Error(err, "Failed to provision storage credentials")
+		return r.markFailed(ctx, kserveDeployment, kserveDeployment.Status.InstalledComponents, err)
+	}
+
+	// Don't apply over a KServe install someone else manages
+	if hasComponent(kserveDeployment, "kserve") {
+		if err := r.checkExistingInstall(ctx, kserveDeployment); err != nil {
+			logger.Error(err, "Existing KServe install found")
+			return r.markFailed(ctx, kserveDeployment, nil, withReason(platformv1alpha1.ReasonAdoptionDisabled, err))
+		}
+	}
+
+	// Control-plane pods can't be created with a PriorityClass that's missing
+	if err := r.checkPriorityClass(ctx, kserveDeployment); err != nil {
+		logger.Error(err, "Invalid priority class")
+		return r.markFailed(ctx, kserveDeployment, kserveDeployment.Status.InstalledComponents, withReason(platformv1alpha1.ReasonDependencyMissing, err))
+	}
+
+	// Fail up front rather than midway when new workloads won't fit the quota
+	if err := r.checkResourceQuotas(ctx, kserveDeployment); err != nil {
+		if shuttingDown(ctx) {
+			return r.abandonReconcile(ctx)
+		}
+		logger.Error(err, "Insufficient resource quota")
+		return r.markFailed(ctx, kserveDeployment, kserveDeployment.Status.InstalledComponents, withReason(platformv1alpha1.ReasonQuotaExceeded, err))
+	}
+
+	// Catch manifests the cluster's API would reject before applying any of them
+	if err := r.checkManifestSchemas(ctx, kserveDeployment); err != nil {
+		if shuttingDown(ctx) {
+			return r.abandonReconcile(ctx)
+		}
+		logger.Error(err, "Manifest validation failed")
+		return r.markFailed(ctx, kserveDeployment, kserveDeployment.Status.InstalledComponents, withReason(platformv1alpha1.ReasonSchemaErrors, err))
+	}
+
+	// Write the manifests this spec resolves to for review when asked to
+	if kserveDeployment.Annotations[renderManifestsAnnotation] == "true" {
+		if err := r.renderManifests(ctx, kserveDeployment); err != nil {
+			if shuttingDown(ctx) {
+				return r.abandonReconcile(ctx)
+			}
+			logger.Error(err, "Failed to render effective manifests")
+		}
+	}
+
+	// A malformed log level override only loses that override
+	if _, err := componentLogLevels(kserveDeployment); err != nil {
+		logger.Error(err, "Ignoring invalid log level override")
+		r.recordEvent(kserveDeployment, corev1.EventTypeWarning, "InvalidLogLevel", err.Error())
+	}
+
+	// Targeted repair: re-deploy only the components named in the annotation
+	reapply, err := requestedReapply(kserveDeployment)
+	if err != nil {
+		logger.Error(err, "Invalid reapply request")
+		return r.markFailed(ctx, kserveDeployment, kserveDeployment.Status.InstalledComponents, withReason(platformv1alpha1.ReasonValidationFailed, err))
+	}
+	if len(reapply) > 0 {
+		return r.reapplyComponents(ctx, kserveDeployment, reapply)
+	}
+
 	// Deploy KServe components
 	installedComponents := []string{}
 
-	// Deploy each requested component
-	for _, component := range kserveDeployment.Spec.Components {
+	// Resume an interrupted install or upgrade from its checkpoint
+	checkpoint := upgradeCheckpoint(kserveDeployment)
+
+	// Skipped components apply nothing, so this reconcile can't tell which
+	// managed resources are stale
+	resumed := false
+
+	// A deployment pinned to a manifest snapshot applies it instead of
+	// fetching anything
+	pinned := manifestSnapshotPinned(kserveDeployment)
+	if pinned {
+		if err := r.applyManifestSnapshot(ctx, kserveDeployment); err != nil {
+			if shuttingDown(ctx) {
+				return r.abandonReconcile(ctx)
+			}
+			logger.Error(err, "Failed to apply the manifest snapshot")
+			return r.markFailed(ctx, kserveDeployment, kserveDeployment.Status.InstalledComponents, err)
+		}
+		installedComponents = append(installedComponents, kserveDeployment.Spec.Components...)
+		for _, ref := range kserveDeployment.Spec.ExtraManifests {
+			installedComponents = append(installedComponents, extraManifestPrefix+ref.Name)
+		}
+	}
+
+	// Deploy each requested component, unless the snapshot already did
+	deploy := kserveDeployment.Spec.Components
+	if pinned {
+		deploy = nil
+	}
+	for _, component := range deploy {
+		if checkpointCompleted(checkpoint, component) {
+			logger.Info("Component already upgraded, skipping", "component", component, "version", checkpoint.TargetVersion)
+			installedComponents = append(installedComponents, component)
+			resumed = true
+			continue
+		}
+
+		if shuttingDown(ctx) {
+			return r.abandonReconcile(ctx)
+		}
+
 		logger.Info("Deploying component", "component", component)
 		
-		if err := r.deployComponent(ctx, kserveDeployment, component); err != nil {
+		changedBefore := changedCount(ctx)
+		if err := r.deployComponentAtomically(ctx, kserveDeployment, component); err != nil {
+			if shuttingDown(ctx) {
+				return r.abandonReconcile(ctx)
+			}
 			logger.Error(err, "Failed to deploy component", "component", component)
-			return r.updateStatus(ctx, kserveDeployment, "Failed", "", installedComponents)
+			return r.markFailed(ctx, kserveDeployment, installedComponents, withReason(platformv1alpha1.ReasonApplyFailed, err))
 		}
 		
 		installedComponents = append(installedComponents, component)
+		r.setComponentStatus(kserveDeployment, component, changedCount(ctx) > changedBefore)
+
+		if err := r.saveCheckpoint(ctx, kserveDeployment, component); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	pruneComponentStatuses(kserveDeployment)
+
+	// Apply extra manifests after the core components. One holding resources
+	// KServe's admission webhooks validate waits until the webhook serves.
+	if !pinned {
+		extraManifests, err := r.deployExtraManifests(ctx, kserveDeployment)
+		installedComponents = append(installedComponents, extraManifests...)
+		if unreachable, ok := asWebhookUnreachable(err); ok {
+			if shuttingDown(ctx) {
+				return r.abandonReconcile(ctx)
+			}
+			logger.Info("KServe webhook not reachable yet, requeueing", "reason", unreachable.Error())
+			r.updateManagedResources(ctx, kserveDeployment, false)
+			result, err := r.updateStatusWithReason(ctx, kserveDeployment, "Installing", platformv1alpha1.ReasonWaitingForReadiness, kserveDeployment.Status.InstalledVersion, installedComponents, unreachable.Error())
+			if err == nil {
+				result.RequeueAfter = webhookProbeInterval
+			}
+			return result, err
+		}
+		if err != nil {
+			if shuttingDown(ctx) {
+				return r.abandonReconcile(ctx)
+			}
+			logger.Error(err, "Failed to apply extra manifests")
+			return r.markFailed(ctx, kserveDeployment, installedComponents, withReason(platformv1alpha1.ReasonApplyFailed, err))
+		}
 	}
 
-	// Update status to Ready
-	return r.updateStatus(ctx, kserveDeployment, "Ready", kserveDeployment.Spec.Version, installedComponents)
+	// Every manifest source answered, so none of them are unavailable
+	meta.RemoveStatusCondition(&kserveDeployment.Status.Conditions, "SourceUnavailable")
+	setFieldConflictCondition(ctx, kserveDeployment)
+	setSlowSourceCondition(ctx, kserveDeployment)
+	setRetryBudgetCondition(ctx, kserveDeployment)
+	r.setImagePullCondition(ctx, kserveDeployment)
+
+	// Confirm the applied ServingRuntimes can actually serve
+	verifyingRuntimes := r.verifyServingRuntimes(ctx, kserveDeployment)
+
+	// All components are at the desired version
+	kserveDeployment.Status.UpgradeCheckpoint = nil
+
+	// Run post-install Jobs now that the core install is in place
+	phase, reason, message := "Ready", platformv1alpha1.ReasonInstallComplete, ""
+	pending, err := r.reconcilePostInstallJobs(ctx, kserveDeployment)
+	if err != nil {
+		if shuttingDown(ctx) {
+			return r.abandonReconcile(ctx)
+		}
+		logger.Error(err, "Post-install Jobs did not succeed")
+		phase, reason = "Degraded", platformv1alpha1.ReasonPostInstallJobFailed
+		message = fmt.Sprintf("KServe deployment is degraded: %v", err)
+	}
+
+	// Pods that can't pull their images leave the install unusable
+	if meta.IsStatusConditionTrue(kserveDeployment.Status.Conditions, "ImagePullFailed") {
+		phase, reason = "Degraded", platformv1alpha1.ReasonImagePullBackOff
+		message = "KServe deployment is degraded: pods can't pull their images"
+	}
+
+	// The serving stack isn't Ready until its models are
+	phase, modelsReason, modelsMessage, waitingForModels := r.gateOnInferenceServices(ctx, kserveDeployment, phase)
+	if modelsMessage != "" {
+		reason, message = modelsReason, modelsMessage
+	}
+
+	// Only report Ready again once the deployment has stayed healthy for a while
+	phase, recovering, recheckAfter := r.stabilizePhase(kserveDeployment, phase)
+	if recovering != "" {
+		reason, message = platformv1alpha1.ReasonStabilizing, recovering
+	}
+
+	// Record what was applied and delete what no longer is, including what a
+	// component left behind in the namespace it moved out of
+	r.updateManagedResources(ctx, kserveDeployment, !resumed)
+	if !resumed {
+		r.pruneUnusedNamespaces(ctx, kserveDeployment)
+	}
+
+	// Pin what was just installed when asked to
+	r.reconcileManifestSnapshot(ctx, kserveDeployment, phase, !resumed)
+
+	recordTimeToReady(kserveDeployment, phase)
+
+	// Update status to Ready (or Degraded when a check above failed)
+	result, err = r.updateStatusWithReason(ctx, kserveDeployment, phase, reason, kserveDeployment.Spec.Version, installedComponents, message)
+	if err == nil && pending {
+		result.RequeueAfter = postInstallJobPollInterval
+	}
+	if err == nil && recheckAfter > 0 && (result.RequeueAfter == 0 || recheckAfter < result.RequeueAfter) {
+		result.RequeueAfter = recheckAfter
+	}
+	if err == nil && verifyingRuntimes && (result.RequeueAfter == 0 || runtimeVerificationPollInterval < result.RequeueAfter) {
+		result.RequeueAfter = runtimeVerificationPollInterval
+	}
+	if err == nil && retryBudgetExhausted(ctx) && (result.RequeueAfter == 0 || retryBudgetBackoff < result.RequeueAfter) {
+		result.RequeueAfter = retryBudgetBackoff
+	}
+	if err == nil && waitingForModels && (result.RequeueAfter == 0 || inferenceServicePollInterval < result.RequeueAfter) {
+		result.RequeueAfter = inferenceServicePollInterval
+	}
+	return result, err
 }
 
-func (r *KServeDeploymentReconciler) deployComponent(ctx context.Context, kd *platformv1alpha1.KServeDeployment, component string) error {
+func (r *KServeDeploymentReconciler) deployComponent(ctx context.Context, kd *platformv1alpha1.KServeDeployment, component string) (err error) {
+	ctx = withComponentLogger(ctx, kd, component)
 	logger := log.FromContext(ctx)
+
+	ctx, span := startSpan(ctx, "DeployComponent", attribute.String("component", component))
+	defer func() { endSpan(span, err) }()
 	
+	// Keep appliedComponents in step with the components handled here
 	switch component {
 	case "kserve":
 		return r.deployKServe(ctx, kd)
@@ -95,13 +514,13 @@ func (r *KServeDeploymentReconciler) deployKServe(ctx context.Context, kd *platf
 	logger := log.FromContext(ctx)
 	logger.Info("Deploying KServe", "version", kd.Spec.Version)
 	
-	manifestURL := fmt.Sprintf("https://github.com/kserve/kserve/releases/download/%s/kserve.yaml", kd.Spec.Version)
+	manifestURL := kserveManifestURL(kd.Spec.Version)
 	logger.Info("Applying KServe manifests", "url", manifestURL)
 	
 	// Use kubectl to apply the manifests
 	// In a production operator, you'd parse YAML and use the Kubernetes API client
 	// For this prototype, we'll use kubectl which is simpler
-	if err := r.applyManifestURL(ctx, manifestURL); err != nil {
+	if err := r.applyManifestURL(ctx, kd, "kserve", manifestURL); err != nil {
 		logger.Error(err, "Failed to apply KServe manifests")
 		return err
 	}
@@ -110,16 +529,22 @@ func (r *KServeDeploymentReconciler) deployKServe(ctx context.Context, kd *platf
 	
 	// Apply RawDeployment mode configuration
 	logger.Info("Configuring KServe for RawDeployment mode")
-	if err := r.configureRawDeployment(ctx); err != nil {
+	if err := r.configureRawDeployment(ctx, kd); err != nil {
 		logger.Error(err, "Failed to configure RawDeployment mode")
 		return err
 	}
 	
 	logger.Info("KServe configured for RawDeployment mode")
+
+	// Toggle the KServe features requested in the spec
+	if err := r.applyFeatureFlags(ctx, kd); err != nil {
+		logger.Error(err, "Failed to apply feature flags")
+		return err
+	}
 	
 	// Deploy the inference service
 	logger.Info("Deploying inference service")
-	if err := r.deployInferenceService(ctx); err != nil {
+	if err := r.deployInferenceService(ctx, kd); err != nil {
 		logger.Error(err, "Failed to deploy inference service")
 		return err
 	}
@@ -135,7 +560,7 @@ func (r *KServeDeploymentReconciler) deployCertManager(ctx context.Context, kd *
 	manifestURL := "https://github.com/cert-manager/cert-manager/releases/download/v1.13.0/cert-manager.yaml"
 	logger.Info("Applying cert-manager manifests", "url", manifestURL)
 	
-	if err := r.applyManifestURL(ctx, manifestURL); err != nil {
+	if err := r.applyManifestURL(ctx, kd, "cert-manager", manifestURL); err != nil {
 		logger.Error(err, "Failed to apply cert-manager manifests")
 		return err
 	}
@@ -144,74 +569,43 @@ func (r *KServeDeploymentReconciler) deployCertManager(ctx context.Context, kd *
 	return nil
 }
 
-func (r *KServeDeploymentReconciler) applyManifestURL(ctx context.Context, url string) error {
+// applyManifestURL applies the manifests of component found at url. Namespaced
+// resources that don't declare a namespace go to the component's namespace,
+// and so do the ones declared in the namespace upstream expects when the
+// component's namespace was overridden.
+func (r *KServeDeploymentReconciler) applyManifestURL(ctx context.Context, kd *platformv1alpha1.KServeDeployment, component, url string) error {
 	logger := log.FromContext(ctx)
 	
 	// Fetch the manifest from URL
-	logger.Info("Fetching manifest", "url", url)
-	resp, err := http.Get(url)
+	manifestBytes, err := r.fetchManifest(ctx, url, "")
 	if err != nil {
-		return fmt.Errorf("failed to fetch manifest: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
 	
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to fetch manifest: status %d", resp.StatusCode)
+	// Don't update ConfigMaps - they may have been customized
+	opts := applyOptionsFor(kd)
+	opts.defaultNamespace = componentNamespace(kd, component)
+	opts.skipExistingConfigMaps = true
+	if upstream := upstreamNamespace(component); upstream != opts.defaultNamespace {
+		opts.mutators = append(opts.mutators, relocateNamespace(upstream, opts.defaultNamespace))
 	}
-	
-	// Read the entire response
-	manifestBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read manifest: %w", err)
+	if hasPlacement(kd) {
+		opts.mutators = append(opts.mutators, placeComponentPods(kd))
 	}
-	
-	// Split YAML documents and apply each one
-	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifestBytes), 4096)
-	for {
-		var obj unstructured.Unstructured
-		if err := decoder.Decode(&obj); err != nil {
-			if err == io.EOF {
-				break
-			}
-			logger.Info("Skipping invalid YAML document", "error", err)
-			continue
-		}
-		
-		if obj.Object == nil {
-			continue
-		}
-		
-		logger.Info("Applying resource", 
-			"kind", obj.GetKind(), 
-			"name", obj.GetName(), 
-			"namespace", obj.GetNamespace())
-		
-		// Try to create the resource
-		if err := r.Create(ctx, &obj); err != nil {
-			if errors.IsAlreadyExists(err) {
-				// Don't update ConfigMaps - they may have been customized
-				if obj.GetKind() == "ConfigMap" {
-					logger.Info("ConfigMap already exists, skipping update", "name", obj.GetName(), "namespace", obj.GetNamespace())
-				} else {
-					logger.Info("Resource already exists, updating", "kind", obj.GetKind(), "name", obj.GetName())
-					// Update the resource
-					if err := r.Update(ctx, &obj); err != nil {
-						logger.Error(err, "Failed to update resource", "kind", obj.GetKind(), "name", obj.GetName())
-						// Continue with other resources even if one fails
-					}
-				}
-			} else {
-				logger.Error(err, "Failed to create resource", "kind", obj.GetKind(), "name", obj.GetName())
-				// Continue with other resources
-			}
-		}
+	if kd.Spec.PriorityClassName != "" {
+		opts.mutators = append(opts.mutators, setPriorityClass(kd.Spec.PriorityClassName))
+	}
+	if _, err := r.applyManifests(ctx, manifestBytes, opts); err != nil {
+		return err
 	}
 	
 	logger.Info("Finished applying manifests from URL")
 	return nil
 }
 
-func (r *KServeDeploymentReconciler) applyManifestFile(ctx context.Context, path string) error {
+// applyManifestFile applies the manifests in path. When namespace is set,
+// namespaced resources are placed there whether or not they declare one.
+func (r *KServeDeploymentReconciler) applyManifestFile(ctx context.Context, kd *platformv1alpha1.KServeDeployment, path, namespace string) error {
 	logger := log.FromContext(ctx)
 	
 	// Read the manifest file
@@ -221,67 +615,27 @@ func (r *KServeDeploymentReconciler) applyManifestFile(ctx context.Context, path
 		return fmt.Errorf("failed to read manifest file: %w", err)
 	}
 	
-	// Split YAML documents and apply each one
-	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifestBytes), 4096)
-	for {
-		var obj unstructured.Unstructured
-		if err := decoder.Decode(&obj); err != nil {
-			if err == io.EOF {
-				break
-			}
-			logger.Info("Skipping invalid YAML document", "error", err)
-			continue
-		}
-		
-		if obj.Object == nil {
-			continue
-		}
-		
-		logger.Info("Applying resource", 
-			"kind", obj.GetKind(), 
-			"name", obj.GetName(), 
-			"namespace", obj.GetNamespace())
-		
-		// Try to create the resource
-		if err := r.Create(ctx, &obj); err != nil {
-			if errors.IsAlreadyExists(err) {
-				logger.Info("Resource already exists, updating", "kind", obj.GetKind(), "name", obj.GetName())
-				
-				// Get the existing resource
-				existing := &unstructured.Unstructured{}
-				existing.SetGroupVersionKind(obj.GroupVersionKind())
-				key := client.ObjectKey{
-					Namespace: obj.GetNamespace(),
-					Name:      obj.GetName(),
-				}
-				
-				if err := r.Get(ctx, key, existing); err != nil {
-					logger.Error(err, "Failed to get existing resource", "kind", obj.GetKind(), "name", obj.GetName())
-					continue
-				}
-				
-				// Update the resource
-				obj.SetResourceVersion(existing.GetResourceVersion())
-				if err := r.Update(ctx, &obj); err != nil {
-					logger.Error(err, "Failed to update resource", "kind", obj.GetKind(), "name", obj.GetName())
-				}
-			} else {
-				logger.Error(err, "Failed to create resource", "kind", obj.GetKind(), "name", obj.GetName())
-			}
-		}
+	opts := applyOptionsFor(kd)
+	if namespace != "" {
+		opts.namespace = namespace
+		opts.defaultNamespace = namespace
+	}
+	if _, err := r.applyManifests(ctx, manifestBytes, opts); err != nil {
+		return err
 	}
 	
 	logger.Info("Finished applying manifests from file")
 	return nil
 }
 
-func (r *KServeDeploymentReconciler) configureRawDeployment(ctx context.Context) error {
+func (r *KServeDeploymentReconciler) configureRawDeployment(ctx context.Context, kd *platformv1alpha1.KServeDeployment) error {
 	logger := log.FromContext(ctx)
-	logger.Info("Applying RawDeployment configuration patch")
+	namespace := componentNamespace(kd, "kserve")
+	logger.Info("Applying RawDeployment configuration patch", "namespace", namespace)
 	
-	// Apply the RawDeployment patch
+	// Apply the RawDeployment patch into the KServe namespace
 	patchPath := "config/kserve-rawdeployment-patch.yaml"
-	if err := r.applyManifestFile(ctx, patchPath); err != nil {
+	if err := r.applyManifestFile(ctx, kd, patchPath, namespace); err != nil {
 		logger.Error(err, "Failed to apply RawDeployment patch")
 		return err
 	}
@@ -290,38 +644,156 @@ func (r *KServeDeploymentReconciler) configureRawDeployment(ctx context.Context)
 	return nil
 }
 
-func (r *KServeDeploymentReconciler) deployInferenceService(ctx context.Context) error {
+func (r *KServeDeploymentReconciler) deployInferenceService(ctx context.Context, kd *platformv1alpha1.KServeDeployment) error {
 	logger := log.FromContext(ctx)
-	logger.Info("Deploying InferenceService from manifest")
 	
-	// Apply the InferenceService manifest
-	manifestPath := "config/operand/gemma2-inferenceservice.yaml"
-	if err := r.applyManifestFile(ctx, manifestPath); err != nil {
+	// Render the InferenceService template with the deployment's spec
+	templatePath := inferenceServiceTemplatePath(kd)
+	logger.Info("Deploying InferenceService from template", "path", templatePath)
+	manifestBytes, err := renderInferenceServiceTemplate(kd, templatePath)
+	if err != nil {
+		logger.Error(err, "Failed to render InferenceService template")
+		return err
+	}
+
+	// Don't point InferenceServices at a runtime that doesn't exist
+	if err := r.checkDefaultRuntimeFor(ctx, kd, manifestBytes); err != nil {
+		logger.Error(err, "Default runtime is not installed")
+		return err
+	}
+	
+	// Stamp each InferenceService so it can be traced back to this deployment
+	opts := applyOptionsFor(kd)
+	opts.mutators = append(opts.mutators, annotateInferenceServices(kd, time.Now()))
+	applied, err := r.applyManifests(ctx, manifestBytes, opts)
+	if err != nil {
 		logger.Error(err, "Failed to apply InferenceService manifest")
 		return err
 	}
+	r.recordInferenceServices(ctx, kd, applied)
 	
 	logger.Info("InferenceService manifest applied successfully")
 	return nil
 }
 
+// namespaceTerminatingPollInterval is how often a namespace that is still
+// being deleted is checked before it is recreated
+const namespaceTerminatingPollInterval = 10 * time.Second
+
+// recordEvent emits an Event on kd when a recorder is configured
+func (r *KServeDeploymentReconciler) recordEvent(kd *platformv1alpha1.KServeDeployment, eventType, reason, message string) {
+	if r.Recorder == nil {
+		return
+	}
+	r.Recorder.Event(kd, eventType, reason, message)
+}
+
+// shuttingDown reports whether the reconcile context was cancelled, which
+// happens when the manager stops on SIGTERM
+func shuttingDown(ctx context.Context) bool {
+	return ctx.Err() != nil
+}
+
+// abandonReconcile ends a reconcile interrupted by shutdown without touching
+// status. The object stays Installing with its upgrade checkpoint, so the next
+// operator instance resumes where this one stopped instead of seeing a
+// spurious Failed.
+func (r *KServeDeploymentReconciler) abandonReconcile(ctx context.Context) (ctrl.Result, error) {
+	log.FromContext(ctx).Info("Operator is shutting down, abandoning reconcile")
+	return ctrl.Result{}, nil
+}
+
 func (r *KServeDeploymentReconciler) execCommand(cmd string) (string, error) {
 	// This function is no longer needed but kept for compatibility
 	return "Command execution not used", nil
 }
 
+// markFailed records a failed install. InstalledVersion keeps the last
+// version that was installed, so the downgrade and canary checks still
+// compare against it. When the failure came from an open circuit breaker it
+// also sets the SourceUnavailable condition and requeues once the cooldown
+// has passed.
+func (r *KServeDeploymentReconciler) markFailed(ctx context.Context, kd *platformv1alpha1.KServeDeployment, components []string, cause error) (ctrl.Result, error) {
+	setFieldConflictCondition(ctx, kd)
+	setSlowSourceCondition(ctx, kd)
+	setRetryBudgetCondition(ctx, kd)
+	r.updateManagedResources(ctx, kd, false)
+
+	unavailable, ok := asSourceUnavailable(cause)
+	if !ok {
+		result, err := r.updateStatusWithReason(ctx, kd, "Failed", failureReason(cause), kd.Status.InstalledVersion, components, cause.Error())
+		if err == nil && retryBudgetExhausted(ctx) {
+			// Retry what the budget couldn't once it has refilled
+			result.RequeueAfter = retryBudgetBackoff
+		}
+		return result, err
+	}
+
+	meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+		Type:               "SourceUnavailable",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kd.Generation,
+		Reason:             platformv1alpha1.ReasonCircuitOpen,
+		Message:            unavailable.Error(),
+	})
+
+	result, err := r.updateStatusWithReason(ctx, kd, "Failed", failureReason(cause), kd.Status.InstalledVersion, components, cause.Error())
+	if err == nil {
+		result.RequeueAfter = unavailable.RetryAfter
+	}
+	return result, err
+}
+
+// setFieldConflictCondition reports the server-side apply conflicts seen
+// during this reconcile, clearing the condition when there were none
+func setFieldConflictCondition(ctx context.Context, kd *platformv1alpha1.KServeDeployment) {
+	state := reconcileStateFrom(ctx)
+	if state == nil || len(state.fieldConflicts) == 0 {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "FieldConflict")
+		return
+	}
+
+	meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+		Type:               "FieldConflict",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kd.Generation,
+		Reason:             platformv1alpha1.ReasonFieldManagerConflict,
+		Message:            strings.Join(state.fieldConflicts, "; "),
+	})
+}
+
 func (r *KServeDeploymentReconciler) updateStatus(ctx context.Context, kd *platformv1alpha1.KServeDeployment, phase, version string, components []string) (ctrl.Result, error) {
+	return r.updateStatusWithMessage(ctx, kd, phase, version, components, "")
+}
+
+// updateStatusWithMessage is updateStatus with a specific Ready condition
+// message in place of the default one for the phase
+func (r *KServeDeploymentReconciler) updateStatusWithMessage(ctx context.Context, kd *platformv1alpha1.KServeDeployment, phase, version string, components []string, message string) (ctrl.Result, error) {
+	return r.updateStatusWithReason(ctx, kd, phase, phaseReason(phase), version, components, message)
+}
+
+// updateStatusWithReason is updateStatusWithMessage with a specific Ready
+// condition reason in place of the default one for the phase
+func (r *KServeDeploymentReconciler) updateStatusWithReason(ctx context.Context, kd *platformv1alpha1.KServeDeployment, phase, reason, version string, components []string, message string) (ctrl.Result, error) {
 	kd.Status.Phase = phase
 	kd.Status.InstalledVersion = version
-	kd.Status.InstalledComponents = components
+	kd.Status.InstalledComponents = uniqueStrings(components)
 	kd.Status.LastUpdated = metav1.Now()
+	kd.Status.ObservedSpecHash = specHash(kd)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("phase", phase))
+
+	if state := reconcileStateFrom(ctx); state != nil {
+		lastReconcile := kd.Status.LastUpdated
+		kd.Status.LastReconcileTime = &lastReconcile
+		kd.Status.LastReconcileDuration = &metav1.Duration{Duration: time.Since(state.start)}
+	}
 
 	condition := metav1.Condition{
 		Type:               "Ready",
 		Status:             metav1.ConditionTrue,
 		ObservedGeneration: kd.Generation,
 		LastTransitionTime: metav1.Now(),
-		Reason:             phase,
+		Reason:             reason,
 		Message:            fmt.Sprintf("KServe deployment is %s", phase),
 	}
 
@@ -330,18 +802,86 @@ func (r *KServeDeploymentReconciler) updateStatus(ctx context.Context, kd *platf
 		condition.Message = "KServe deployment failed"
 	}
 
-	kd.Status.Conditions = []metav1.Condition{condition}
+	if phase == "Degraded" {
+		condition.Status = metav1.ConditionFalse
+		condition.Message = "KServe deployment is degraded"
+	}
+
+	if phase == "Pending" || phase == "Terminating" {
+		condition.Status = metav1.ConditionFalse
+	}
+
+	if message != "" {
+		condition.Message = message
+	}
+
+	meta.SetStatusCondition(&kd.Status.Conditions, condition)
+
+	// Come back for the next scheduled re-apply
+	now := time.Now()
+	start := now
+	if state := reconcileStateFrom(ctx); state != nil {
+		start = state.start
+	}
+	untilScheduled := scheduleNextReconcile(kd, start, now)
 
 	if err := r.Status().Update(ctx, kd); err != nil {
 		return ctrl.Result{}, err
 	}
 
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: untilScheduled}, nil
+}
+
+// resyncPredicate passes the periodic resync of the informer, which delivers
+// an update with an unchanged resourceVersion
+var resyncPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return e.ObjectOld.GetResourceVersion() == e.ObjectNew.GetResourceVersion()
+	},
+	CreateFunc:  func(event.CreateEvent) bool { return false },
+	DeleteFunc:  func(event.DeleteEvent) bool { return false },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// specHash returns a stable hash of the spec. encoding/json emits struct
+// fields in declaration order and sorts map keys, so an unchanged spec always
+// hashes the same.
+func specHash(kd *platformv1alpha1.KServeDeployment) string {
+	data, err := json.Marshal(kd.Spec)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *KServeDeploymentReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&platformv1alpha1.KServeDeployment{}).
-		Complete(r)
+	// Status writes bump the resourceVersion but not the generation; filtering
+	// on generation/annotation changes keeps those writes from re-triggering us.
+	// Periodic resyncs still get through so drift (e.g. a deleted namespace)
+	// is healed.
+	if err := indexReferences(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+
+	blder := ctrl.NewControllerManagedBy(mgr).
+		For(&platformv1alpha1.KServeDeployment{}, builder.WithPredicates(
+			predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}, resyncPredicate),
+		)).
+		// Re-apply extra manifests when the labeled ConfigMap they are read
+		// from changes
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.requestsForConfigMap)).
+		// Copy rotated pull secrets and restore deleted copies
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(r.requestsForSecret))
+
+	// Pause or resume everything with the pause ConfigMap
+	if r.PauseConfigMap.Name != "" {
+		src, err := r.pauseConfigMapSource(mgr)
+		if err != nil {
+			return err
+		}
+		blder = blder.WatchesRawSource(src, handler.EnqueueRequestsFromMapFunc(r.requestsForConfigMap))
+	}
+	return blder.Complete(r)
 }
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// recordApplied notes a resource applied during the current reconcile
+func recordApplied(ctx context.Context, ref platformv1alpha1.ResourceRef) {
+	if state := reconcileStateFrom(ctx); state != nil {
+		state.applied = append(state.applied, ref)
+	}
+}
+
+// recordApplyFailure notes a resource the current reconcile tried and failed
+// to apply; it is kept in ManagedResources rather than pruned
+func recordApplyFailure(ctx context.Context, ref platformv1alpha1.ResourceRef) {
+	if state := reconcileStateFrom(ctx); state != nil {
+		state.failed = append(state.failed, ref)
+	}
+}
+
+// recordChanged counts a resource the current reconcile created or modified
+func recordChanged(ctx context.Context) {
+	if state := reconcileStateFrom(ctx); state != nil {
+		state.changed++
+	}
+}
+
+// changedCount returns how many resources the current reconcile created or
+// modified so far
+func changedCount(ctx context.Context) int {
+	if state := reconcileStateFrom(ctx); state != nil {
+		return state.changed
+	}
+	return 0
+}
+
+// failedCount returns how many resources the current reconcile failed to
+// apply so far, to be passed to failedSince
+func failedCount(ctx context.Context) int {
+	if state := reconcileStateFrom(ctx); state != nil {
+		return len(state.failed)
+	}
+	return 0
+}
+
+// failedSince returns the resources that failed to apply since failedCount
+// returned before
+func failedSince(ctx context.Context, before int) map[platformv1alpha1.ResourceRef]bool {
+	failed := map[platformv1alpha1.ResourceRef]bool{}
+	if state := reconcileStateFrom(ctx); state != nil {
+		for _, ref := range state.failed[before:] {
+			failed[ref] = true
+		}
+	}
+	return failed
+}
+
+// updateManagedResources records everything this reconcile applied in
+// Status.ManagedResources. With prune set the reconcile applied the complete
+// desired state, so previously managed resources it no longer applies are
+// deleted; otherwise (a failed or partial reconcile) they are kept.
+func (r *KServeDeploymentReconciler) updateManagedResources(ctx context.Context, kd *platformv1alpha1.KServeDeployment, prune bool) {
+	state := reconcileStateFrom(ctx)
+	if state == nil {
+		return
+	}
+
+	seen := map[platformv1alpha1.ResourceRef]bool{}
+	managed := []platformv1alpha1.ResourceRef{}
+	for _, ref := range state.applied {
+		if !seen[ref] {
+			seen[ref] = true
+			managed = append(managed, ref)
+		}
+	}
+
+	// Namespaces the manifests created are only deleted through
+	// DeleteNamespaceOnCleanup, like the ones ensureNamespaces creates
+	for _, ref := range state.created {
+		if ref.APIVersion == "v1" && ref.Kind == "Namespace" {
+			recordCreatedNamespaces(kd, []string{ref.Name})
+		}
+	}
+
+	keep := map[platformv1alpha1.ResourceRef]bool{}
+	for _, ref := range state.failed {
+		keep[ref] = true
+	}
+
+	stale := []platformv1alpha1.ResourceRef{}
+	for _, ref := range kd.Status.ManagedResources {
+		if seen[ref] {
+			continue
+		}
+		if prune && !keep[ref] {
+			stale = append(stale, ref)
+			continue
+		}
+		seen[ref] = true
+		managed = append(managed, ref)
+	}
+
+	if len(stale) > 0 {
+		log.FromContext(ctx).Info("Pruning resources no longer in the desired state", "count", len(stale))
+		r.deleteResources(ctx, kd, reverseResourceRefs(stale))
+	}
+	kd.Status.ManagedResources = managed
+}
+
+// reverseResourceRefs returns refs in reverse order, so resources are deleted
+// before the definitions (CRDs, namespaces) they depend on
+func reverseResourceRefs(refs []platformv1alpha1.ResourceRef) []platformv1alpha1.ResourceRef {
+	reversed := make([]platformv1alpha1.ResourceRef, 0, len(refs))
+	for i := len(refs) - 1; i >= 0; i-- {
+		reversed = append(reversed, refs[i])
+	}
+	return reversed
+}
+
+// resourceKey identifies a resource regardless of the version it was applied at
+type resourceKey struct {
+	schema.GroupKind
+	Namespace string
+	Name      string
+}
+
+func resourceKeyFor(ref platformv1alpha1.ResourceRef) resourceKey {
+	return resourceKey{
+		GroupKind: schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind).GroupKind(),
+		Namespace: ref.Namespace,
+		Name:      ref.Name,
+	}
+}
+
+// sharedResources returns the resources other KServeDeployments manage, which
+// deleting kd or pruning its resources must leave alone
+func (r *KServeDeploymentReconciler) sharedResources(ctx context.Context, kd *platformv1alpha1.KServeDeployment) (map[resourceKey]bool, error) {
+	list := &platformv1alpha1.KServeDeploymentList{}
+	if err := r.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to list KServeDeployments: %w", err)
+	}
+
+	shared := map[resourceKey]bool{}
+	for i := range list.Items {
+		other := &list.Items[i]
+		if other.Namespace == kd.Namespace && other.Name == kd.Name {
+			continue
+		}
+		for _, ref := range other.Status.ManagedResources {
+			shared[resourceKeyFor(ref)] = true
+		}
+	}
+	return shared, nil
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ManifestCache keeps fetched manifests on disk (typically a PVC) so repeated
+// reconciles don't download the same manifest again. Entries are keyed by URL
+// and expected checksum, so changing a manifest's checksum invalidates its
+// entry, and expire after TTL. A nil cache caches nothing.
+type ManifestCache struct {
+	Dir string
+	TTL time.Duration
+}
+
+// NewManifestCache returns a cache in dir, creating the directory if needed
+func NewManifestCache(dir string, ttl time.Duration) (*ManifestCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create manifest cache directory: %w", err)
+	}
+	return &ManifestCache{Dir: dir, TTL: ttl}, nil
+}
+
+// Get returns the cached manifest for url, if there is a fresh entry whose
+// content still matches checksum
+func (c *ManifestCache) Get(url, checksum string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	path := c.path(url, checksum)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > c.TTL {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	if verifyChecksum(data, checksum) != nil {
+		// Corrupted entry; drop it and fetch again
+		os.Remove(path)
+		return nil, false
+	}
+	return data, true
+}
+
+// Put stores the manifest for url. The write goes through a temporary file so
+// concurrent readers never see a partial entry.
+func (c *ManifestCache) Put(url, checksum string, data []byte) error {
+	if c == nil {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(c.Dir, ".manifest-*")
+	if err != nil {
+		return fmt.Errorf("failed to cache manifest: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to cache manifest: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to cache manifest: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(url, checksum)); err != nil {
+		return fmt.Errorf("failed to cache manifest: %w", err)
+	}
+	return nil
+}
+
+func (c *ManifestCache) path(url, checksum string) string {
+	sum := sha256.Sum256([]byte(url + "\x00" + checksum))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".yaml")
+}
+
+// ChecksumMismatchError is returned when a manifest doesn't match the
+// checksum declared for it
+type ChecksumMismatchError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("manifest checksum mismatch: expected sha256 %s, got %s", e.Expected, e.Actual)
+}
+
+// verifyChecksum checks data against a hex-encoded SHA-256 checksum. An empty
+// checksum accepts anything.
+func verifyChecksum(data []byte, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256(data)
+	actual := hex.EncodeToString(sum[:])
+	if !strings.EqualFold(actual, checksum) {
+		return &ChecksumMismatchError{Expected: checksum, Actual: actual}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// resnapshotAnnotation asks a PinManifests deployment to reconcile from its
+// manifest sources again and replace the snapshot once that install is
+// Ready. The operator removes it after the new snapshot is taken.
+const resnapshotAnnotation = "platform.ai-platform.io/resnapshot"
+
+// manifestSnapshotName is the ConfigMap holding a KServeDeployment's pinned
+// manifest snapshot
+func manifestSnapshotName(kd *platformv1alpha1.KServeDeployment) string {
+	return kd.Name + "-manifest-snapshot"
+}
+
+// manifestSnapshotPinned reports whether kd reconciles toward its snapshot
+// rather than its manifest sources. A version bump or the resnapshot
+// annotation unpins it until a new snapshot is taken.
+func manifestSnapshotPinned(kd *platformv1alpha1.KServeDeployment) bool {
+	snapshot := kd.Status.ManifestSnapshot
+	return kd.Spec.PinManifests && snapshot != nil && snapshot.Version == kd.Spec.Version &&
+		kd.Annotations[resnapshotAnnotation] == ""
+}
+
+// loadManifestSnapshot reads the snapshot and checks it against the checksum
+// recorded when it was taken, so an edited snapshot is never applied
+func (r *KServeDeploymentReconciler) loadManifestSnapshot(ctx context.Context, kd *platformv1alpha1.KServeDeployment) ([]byte, error) {
+	manifests, err := r.readManifestsConfigMap(ctx, kd, manifestSnapshotName(kd))
+	if err != nil {
+		return nil, withReason(platformv1alpha1.ReasonDependencyMissing,
+			fmt.Errorf("failed to read the manifest snapshot (annotate with %s=true to take a new one): %w", resnapshotAnnotation, err))
+	}
+	if err := verifyChecksum(manifests, kd.Status.ManifestSnapshot.Checksum); err != nil {
+		return nil, fmt.Errorf("manifest snapshot %s was modified: %w", manifestSnapshotName(kd), err)
+	}
+	return manifests, nil
+}
+
+// applyManifestSnapshot applies the pinned snapshot in place of the
+// components and extra manifests. Nothing is fetched; only the feature flags,
+// which patch KServe's live configuration, are set from the spec.
+//
+// The snapshot is applied in one pass, in the order it was installed in. CRDs
+// still go first and are waited on until Established, and the InferenceService
+// readiness checks that follow the apply still run. The per-component steps of
+// deployComponent and deployExtraManifests don't: there are no upgrade
+// checkpoints, AtomicInstall rollbacks or component statuses, the KServe
+// webhook isn't probed, and the default runtime isn't checked again.
+func (r *KServeDeploymentReconciler) applyManifestSnapshot(ctx context.Context, kd *platformv1alpha1.KServeDeployment) error {
+	manifests, err := r.loadManifestSnapshot(ctx, kd)
+	if err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Info("Applying pinned manifest snapshot", "configmap", manifestSnapshotName(kd),
+		"version", kd.Status.ManifestSnapshot.Version, "resources", kd.Status.ManifestSnapshot.Resources)
+	applied, err := r.applyManifests(ctx, manifests, applyOptionsFor(kd))
+	if err != nil {
+		return withReason(platformv1alpha1.ReasonApplyFailed, err)
+	}
+	// The readiness gate reads the InferenceServices from status
+	r.recordInferenceServices(ctx, kd, applied)
+
+	if hasComponent(kd, "kserve") {
+		if err := r.applyFeatureFlags(ctx, kd); err != nil {
+			return withReason(platformv1alpha1.ReasonApplyFailed, err)
+		}
+	}
+	return nil
+}
+
+// reconcileManifestSnapshot takes a snapshot of the manifests once a
+// PinManifests deployment is Ready and not yet pinned, and drops the
+// snapshot when PinManifests is turned off. complete reports whether this
+// reconcile applied every component, rather than resuming past some. A
+// failed snapshot leaves the deployment reconciling from its sources and is
+// retried on the next reconcile.
+func (r *KServeDeploymentReconciler) reconcileManifestSnapshot(ctx context.Context, kd *platformv1alpha1.KServeDeployment, phase string, complete bool) {
+	logger := log.FromContext(ctx)
+
+	if !kd.Spec.PinManifests {
+		if kd.Status.ManifestSnapshot == nil {
+			return
+		}
+		cm := &corev1.ConfigMap{}
+		cm.Name = manifestSnapshotName(kd)
+		cm.Namespace = kd.Namespace
+		if err := r.Delete(ctx, cm); err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "Failed to delete the manifest snapshot", "configmap", cm.Name)
+			return
+		}
+		kd.Status.ManifestSnapshot = nil
+		return
+	}
+
+	if phase != "Ready" || manifestSnapshotPinned(kd) {
+		return
+	}
+
+	// Only a reconcile that applied the whole install, without failures,
+	// saw everything the snapshot has to hold
+	if !complete || failedCount(ctx) > 0 {
+		logger.V(1).Info("Not taking the manifest snapshot, this reconcile didn't apply the whole install")
+		return
+	}
+
+	if err := r.takeManifestSnapshot(ctx, kd); err != nil {
+		logger.Error(err, "Failed to take the manifest snapshot")
+		r.recordEvent(kd, corev1.EventTypeWarning, "SnapshotFailed", err.Error())
+	}
+}
+
+// takeManifestSnapshot stores the objects this reconcile applied in the
+// snapshot ConfigMap and records it in status, clearing a resnapshot request.
+// Nothing is fetched or rendered again, so the snapshot is exactly what was
+// installed.
+func (r *KServeDeploymentReconciler) takeManifestSnapshot(ctx context.Context, kd *platformv1alpha1.KServeDeployment) error {
+	state := reconcileStateFrom(ctx)
+	if state == nil || len(state.appliedObjects) == 0 {
+		return fmt.Errorf("no applied resources to snapshot")
+	}
+	rendered := state.appliedObjects
+	manifests, err := encodeManifests(rendered)
+	if err != nil {
+		return err
+	}
+	if err := r.writeManifestsConfigMap(ctx, kd, manifestSnapshotName(kd), manifests, len(rendered)); err != nil {
+		return err
+	}
+
+	if _, requested := kd.Annotations[resnapshotAnnotation]; requested {
+		// Clear the annotation on a copy so the in-memory object keeps the
+		// changes this reconcile already made
+		cleared := kd.DeepCopy()
+		delete(cleared.Annotations, resnapshotAnnotation)
+		if err := r.Patch(ctx, cleared, client.MergeFrom(kd)); err != nil {
+			return fmt.Errorf("failed to clear %s annotation: %w", resnapshotAnnotation, err)
+		}
+		kd.Annotations = cleared.Annotations
+		kd.ResourceVersion = cleared.ResourceVersion
+	}
+
+	sum := sha256.Sum256(manifests)
+	kd.Status.ManifestSnapshot = &platformv1alpha1.ManifestSnapshotStatus{
+		ConfigMap: manifestSnapshotName(kd),
+		Version:   kd.Spec.Version,
+		Checksum:  hex.EncodeToString(sum[:]),
+		Resources: len(rendered),
+		TakenAt:   metav1.Now(),
+	}
+	log.FromContext(ctx).Info("Took manifest snapshot", "configmap", manifestSnapshotName(kd), "version", kd.Spec.Version, "resources", len(rendered))
+	r.recordEvent(kd, corev1.EventTypeNormal, "ManifestsSnapshotted",
+		fmt.Sprintf("Pinned %d resources of version %s in ConfigMap %s", len(rendered), kd.Spec.Version, manifestSnapshotName(kd)))
+	return nil
+}
+
+// recordAppliedObject keeps obj, as it was sent, for the manifest snapshot
+func recordAppliedObject(ctx context.Context, obj *unstructured.Unstructured, keepExisting bool) {
+	state := reconcileStateFrom(ctx)
+	if state == nil {
+		return
+	}
+	// The apply computed the same hash, so this can't fail where it succeeded
+	if err := stampRendered(obj, keepExisting); err != nil {
+		return
+	}
+	state.appliedObjects = append(state.appliedObjects, *obj)
+}
+
+// snapshotObjects returns the objects of the pinned snapshot, for the checks
+// that inspect what is about to be applied
+func (r *KServeDeploymentReconciler) snapshotObjects(ctx context.Context, kd *platformv1alpha1.KServeDeployment) ([]unstructured.Unstructured, error) {
+	manifests, err := r.loadManifestSnapshot(ctx, kd)
+	if err != nil {
+		return nil, err
+	}
+	return decodeManifests(ctx, manifests), nil
+}
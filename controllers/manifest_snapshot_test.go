@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const snapshotManifest = `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: inferenceservice-config
+  namespace: kserve
+data:
+  deploy: '{"defaultDeploymentMode": "Serverless"}'
+`
+
+func TestAppliedObjectsAreKeptAsSent(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	r := &KServeDeploymentReconciler{Client: c}
+
+	ctx := withReconcileState(context.Background(), time.Now())
+	opts := applyOptions{keepApplied: true, skipExistingConfigMaps: true}
+	if _, err := r.applyManifests(ctx, []byte(snapshotManifest), opts); err != nil {
+		t.Fatal(err)
+	}
+
+	kept := reconcileStateFrom(ctx).appliedObjects
+	if len(kept) != 1 {
+		t.Fatalf("kept %d objects, want 1", len(kept))
+	}
+	obj := kept[0]
+	if obj.GetResourceVersion() != "" || obj.GetUID() != "" {
+		t.Errorf("kept object carries server fields: resourceVersion %q, uid %q", obj.GetResourceVersion(), obj.GetUID())
+	}
+	annotations := obj.GetAnnotations()
+	if annotations[contentHashAnnotation] == "" {
+		t.Errorf("kept object has no %s annotation", contentHashAnnotation)
+	}
+	if annotations[keepExistingAnnotation] != "true" {
+		t.Errorf("kept ConfigMap isn't marked %s", keepExistingAnnotation)
+	}
+
+	// The create filled in the object it was given, not the one kept
+	live := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: "kserve", Name: "inferenceservice-config"}, live); err != nil {
+		t.Fatal(err)
+	}
+	if live.ResourceVersion == "" {
+		t.Fatal("ConfigMap was not created")
+	}
+
+	// Without keepApplied nothing is kept
+	ctx = withReconcileState(context.Background(), time.Now())
+	if _, err := r.applyManifests(ctx, []byte(snapshotManifest), applyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if kept := reconcileStateFrom(ctx).appliedObjects; len(kept) != 0 {
+		t.Errorf("kept %d objects without keepApplied, want 0", len(kept))
+	}
+}
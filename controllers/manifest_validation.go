@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// maxReportedSchemaFindings caps the findings listed in the condition message
+const maxReportedSchemaFindings = 10
+
+// checkManifestSchemas dry-runs every object about to be applied, reporting
+// what the API server would reject, unknown fields included, in a
+// ManifestSchemaMismatch condition. In Error mode findings fail the
+// reconcile before anything is applied; in Warn mode they are only
+// reported. Kinds the cluster doesn't serve yet (custom resources whose CRD
+// is in the same manifest) are skipped.
+func (r *KServeDeploymentReconciler) checkManifestSchemas(ctx context.Context, kd *platformv1alpha1.KServeDeployment) error {
+	logger := log.FromContext(ctx)
+
+	mode := kd.Spec.ManifestValidation
+	if mode == "" || mode == "Disabled" {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "ManifestSchemaMismatch")
+		return nil
+	}
+
+	rendered, err := r.renderObjects(ctx, kd)
+	if err != nil {
+		// The install itself reports why the manifests can't be applied
+		logger.V(1).Info("Skipping manifest validation, manifests did not render", "error", err.Error())
+		return nil
+	}
+
+	findings, err := r.schemaFindings(ctx, rendered)
+	if err != nil {
+		return err
+	}
+	if len(findings) == 0 {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "ManifestSchemaMismatch")
+		return nil
+	}
+
+	logger.Info("Manifests don't match the cluster's API schema", "mode", mode, "findings", findings)
+
+	reported := findings
+	if len(reported) > maxReportedSchemaFindings {
+		reported = append(reported[:maxReportedSchemaFindings:maxReportedSchemaFindings],
+			fmt.Sprintf("and %d more", len(findings)-maxReportedSchemaFindings))
+	}
+	message := strings.Join(reported, "; ")
+	reason := platformv1alpha1.ReasonSchemaWarnings
+	if mode == "Error" {
+		reason = platformv1alpha1.ReasonSchemaErrors
+	}
+	meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+		Type:               "ManifestSchemaMismatch",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kd.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+
+	if mode == "Error" {
+		return fmt.Errorf("manifests don't match the cluster's API schema: %s", message)
+	}
+	return nil
+}
+
+// schemaFindings applies objects with a server-side dry run and strict field
+// validation, so the API server checks them against its schema, admission
+// included, without persisting anything. It returns the sorted findings,
+// each prefixed with the object it is about. Objects the server can't take
+// yet, because their kind isn't served or their namespace doesn't exist, are
+// skipped.
+func (r *KServeDeploymentReconciler) schemaFindings(ctx context.Context, objects []unstructured.Unstructured) ([]string, error) {
+	logger := log.FromContext(ctx)
+
+	findings := []string{}
+	for i := range objects {
+		obj := objects[i].DeepCopy()
+		obj.SetManagedFields(nil)
+		obj.SetResourceVersion("")
+
+		err := r.Patch(ctx, obj, client.Apply, strictFieldValidation, client.DryRunAll, client.FieldOwner(fieldManager), client.ForceOwnership)
+		switch {
+		case err == nil:
+		case errors.IsInvalid(err) || errors.IsBadRequest(err):
+			for _, finding := range dryRunFindings(err) {
+				findings = append(findings, fmt.Sprintf("%s: %s", objectID(obj), finding))
+			}
+		case meta.IsNoMatchError(err) || errors.IsNotFound(err):
+			logger.V(1).Info("Cluster can't validate object yet", "kind", obj.GetKind(), "name", obj.GetName(), "error", err.Error())
+		default:
+			return nil, fmt.Errorf("failed to dry-run %s: %w", objectID(&objects[i]), err)
+		}
+	}
+	sort.Strings(findings)
+	return findings, nil
+}
+
+// strictFieldValidation makes the API server reject unknown and duplicate
+// fields instead of dropping them
+var strictFieldValidation = &client.PatchOptions{Raw: &metav1.PatchOptions{FieldValidation: "Strict"}}
+
+// dryRunFindings turns a rejected dry run into findings, one per field the
+// API server named, or its message when it named none
+func dryRunFindings(err error) []string {
+	var status errors.APIStatus
+	if !stderrors.As(err, &status) {
+		return []string{err.Error()}
+	}
+
+	findings := []string{}
+	if details := status.Status().Details; details != nil {
+		for _, cause := range details.Causes {
+			if cause.Field != "" {
+				findings = append(findings, fmt.Sprintf("%s: %s", cause.Field, cause.Message))
+			} else {
+				findings = append(findings, cause.Message)
+			}
+		}
+	}
+	if len(findings) == 0 {
+		findings = append(findings, status.Status().Message)
+	}
+	return findings
+}
+
+// objectID names obj in reports as "Kind namespace/name", or "Kind name"
+// for cluster-scoped objects
+func objectID(obj *unstructured.Unstructured) string {
+	if obj.GetNamespace() != "" {
+		return fmt.Sprintf("%s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+	}
+	return fmt.Sprintf("%s %s", obj.GetKind(), obj.GetName())
+}
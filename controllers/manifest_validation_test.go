@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestSchemaFindings(t *testing.T) {
+	deploymentGK := schema.GroupKind{Group: "apps", Kind: "Deployment"}
+
+	tests := []struct {
+		name     string
+		err      error
+		findings []string
+		wantErr  bool
+	}{
+		{
+			name:     "accepted",
+			findings: []string{},
+		},
+		{
+			name: "invalid fields",
+			err: errors.NewInvalid(deploymentGK, "controller", field.ErrorList{
+				field.Invalid(field.NewPath("spec", "replicas"), -1, "must be greater than or equal to 0"),
+				field.Required(field.NewPath("spec", "selector"), ""),
+			}),
+			findings: []string{
+				"Deployment kserve/controller: spec.replicas: Invalid value: -1: must be greater than or equal to 0",
+				"Deployment kserve/controller: spec.selector: Required value",
+			},
+		},
+		{
+			name: "unknown field",
+			err:  errors.NewBadRequest(`.spec.replica: field not declared in schema`),
+			findings: []string{
+				"Deployment kserve/controller: .spec.replica: field not declared in schema",
+			},
+		},
+		{
+			name:     "kind not served",
+			err:      &meta.NoKindMatchError{GroupKind: deploymentGK, SearchedVersions: []string{"v1"}},
+			findings: []string{},
+		},
+		{
+			name:     "namespace missing",
+			err:      errors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "kserve"),
+			findings: []string{},
+		},
+		{
+			name:    "forbidden",
+			err:     errors.NewForbidden(schema.GroupResource{Group: "apps", Resource: "deployments"}, "controller", nil),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patchOpts client.PatchOptions
+			c := fake.NewClientBuilder().
+				WithScheme(runtime.NewScheme()).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+						patchOpts.ApplyOptions(opts)
+						return tt.err
+					},
+				}).
+				Build()
+			r := &KServeDeploymentReconciler{Client: c}
+
+			obj := unstructured.Unstructured{}
+			obj.SetAPIVersion("apps/v1")
+			obj.SetKind("Deployment")
+			obj.SetNamespace("kserve")
+			obj.SetName("controller")
+			obj.SetResourceVersion("42")
+
+			findings, err := r.schemaFindings(context.Background(), []unstructured.Unstructured{obj})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("schemaFindings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(findings, tt.findings) {
+				t.Errorf("schemaFindings() = %q, want %q", findings, tt.findings)
+			}
+
+			if !reflect.DeepEqual(patchOpts.DryRun, []string{metav1.DryRunAll}) {
+				t.Errorf("dry run = %v, want All", patchOpts.DryRun)
+			}
+			if patchOpts.Raw == nil || patchOpts.Raw.FieldValidation != "Strict" {
+				t.Errorf("field validation is not Strict")
+			}
+			if obj.GetResourceVersion() != "42" {
+				t.Errorf("schemaFindings modified the rendered object")
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// reconcileDuration tracks how long each KServeDeployment reconcile takes
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kservedeployment_reconcile_duration_seconds",
+		Help:    "Duration of KServeDeployment reconciles in seconds",
+		Buckets: prometheus.ExponentialBuckets(0.1, 2, 12),
+	}, []string{"result"})
+
+	// timeToReady tracks how long installs take from their first reconcile
+	// (or spec change) to Ready
+	timeToReady = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "kservedeployment_time_to_ready_seconds",
+		Help:    "Time from the start of a KServeDeployment install to Ready in seconds",
+		Buckets: prometheus.ExponentialBuckets(5, 2, 12),
+	})
+
+	// manifestFetchDuration tracks how long manifest downloads take per source host
+	manifestFetchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kservedeployment_manifest_fetch_duration_seconds",
+		Help:    "Duration of successful manifest downloads in seconds",
+		Buckets: prometheus.ExponentialBuckets(0.05, 2, 12),
+	}, []string{"source"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(reconcileDuration, timeToReady, manifestFetchDuration)
+}
+
+func observeReconcile(start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	reconcileDuration.WithLabelValues(result).Observe(time.Since(start).Seconds())
+}
+
+func observeTimeToReady(elapsed time.Duration) {
+	timeToReady.Observe(elapsed.Seconds())
+}
+
+func observeManifestFetch(source string, elapsed time.Duration) {
+	manifestFetchDuration.WithLabelValues(source).Observe(elapsed.Seconds())
+}
@@ -0,0 +1,263 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// maxReportedForeignResources caps the resources listed when a namespace is
+// kept because it holds resources the operator doesn't manage
+const maxReportedForeignResources = 5
+
+// generatedKinds are kinds the cluster creates on its own in every
+// namespace or alongside other resources. They don't stop a namespace from
+// being deleted.
+var generatedKinds = map[schema.GroupKind]bool{
+	{Group: "", Kind: "Event"}:                         true,
+	{Group: "events.k8s.io", Kind: "Event"}:            true,
+	{Group: "", Kind: "Endpoints"}:                     true,
+	{Group: "discovery.k8s.io", Kind: "EndpointSlice"}: true,
+	{Group: "coordination.k8s.io", Kind: "Lease"}:      true,
+}
+
+// checkedKinds are the kinds a namespace is checked for before it is
+// deleted, besides the ones the KServeDeployment manages. They are what
+// workloads in a KServe namespace usually consist of, and kinds the operator
+// may list anyway; Secrets are left out so their data is never read.
+var checkedKinds = []schema.GroupVersionKind{
+	{Group: "", Version: "v1", Kind: "ConfigMap"},
+	{Group: "", Version: "v1", Kind: "Pod"},
+	{Group: "", Version: "v1", Kind: "Service"},
+	{Group: "", Version: "v1", Kind: "ServiceAccount"},
+	{Group: "apps", Version: "v1", Kind: "Deployment"},
+	{Group: "batch", Version: "v1", Kind: "Job"},
+	{Group: "serving.kserve.io", Version: "v1beta1", Kind: "InferenceService"},
+	{Group: "serving.kserve.io", Version: "v1alpha1", Kind: "ServingRuntime"},
+}
+
+// recordCreatedNamespaces adds the namespaces the operator just created to
+// Status.CreatedNamespaces, which decides what cleanup may delete
+func recordCreatedNamespaces(kd *platformv1alpha1.KServeDeployment, created []string) {
+	for _, ns := range created {
+		if !slices.Contains(kd.Status.CreatedNamespaces, ns) {
+			kd.Status.CreatedNamespaces = append(kd.Status.CreatedNamespaces, ns)
+		}
+	}
+}
+
+// deleteCreatedNamespaces deletes the namespaces in Status.CreatedNamespaces
+// once the managed resources are gone. A namespace is kept when another
+// KServeDeployment uses it, when it holds resources the operator doesn't
+// manage, or when that can't be verified. Namespaces the operator didn't
+// create are never deleted.
+func (r *KServeDeploymentReconciler) deleteCreatedNamespaces(ctx context.Context, kd *platformv1alpha1.KServeDeployment) {
+	logger := log.FromContext(ctx)
+	if !kd.Spec.DeleteNamespaceOnCleanup || len(kd.Status.CreatedNamespaces) == 0 {
+		return
+	}
+
+	shared, err := r.sharedNamespaces(ctx, kd)
+	if err != nil {
+		logger.Error(err, "Failed to check for shared namespaces, keeping created namespaces")
+		return
+	}
+
+	for _, name := range kd.Status.CreatedNamespaces {
+		r.deleteCreatedNamespace(ctx, kd, name, shared)
+	}
+}
+
+// pruneUnusedNamespaces deletes the namespaces in Status.CreatedNamespaces
+// that no component needs any more, e.g. after a component's namespace was
+// changed, with the checks and opt-in of deleteCreatedNamespaces. A namespace
+// that is kept stays recorded and is checked again by the next reconcile.
+func (r *KServeDeploymentReconciler) pruneUnusedNamespaces(ctx context.Context, kd *platformv1alpha1.KServeDeployment) {
+	if !kd.Spec.DeleteNamespaceOnCleanup {
+		return
+	}
+
+	required := requiredNamespaces(kd)
+	var shared map[string]bool
+	kept := []string{}
+	for _, name := range kd.Status.CreatedNamespaces {
+		if slices.Contains(required, name) {
+			kept = append(kept, name)
+			continue
+		}
+		if shared == nil {
+			var err error
+			if shared, err = r.sharedNamespaces(ctx, kd); err != nil {
+				log.FromContext(ctx).Error(err, "Failed to check for shared namespaces, keeping unused namespaces")
+				return
+			}
+		}
+		if !r.deleteCreatedNamespace(ctx, kd, name, shared) {
+			kept = append(kept, name)
+		}
+	}
+	kd.Status.CreatedNamespaces = kept
+}
+
+// deleteCreatedNamespace deletes a namespace the operator created unless
+// namespaceRetainReason gives a reason to keep it, and reports whether it did
+func (r *KServeDeploymentReconciler) deleteCreatedNamespace(ctx context.Context, kd *platformv1alpha1.KServeDeployment, name string, shared map[string]bool) bool {
+	logger := log.FromContext(ctx)
+	if reason := r.namespaceRetainReason(ctx, kd, name, shared); reason != "" {
+		logger.Info("Keeping namespace", "namespace", name, "reason", reason)
+		r.recordEvent(kd, corev1.EventTypeNormal, "NamespaceRetained", fmt.Sprintf("Kept namespace %s: %s", name, reason))
+		return false
+	}
+
+	logger.Info("Deleting namespace", "namespace", name)
+	ns := &corev1.Namespace{}
+	ns.SetName(name)
+	if err := r.Delete(ctx, ns); err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Failed to delete namespace", "namespace", name)
+		return false
+	}
+	r.recordEvent(kd, corev1.EventTypeNormal, "NamespaceDeleted", fmt.Sprintf("Deleted namespace %s", name))
+	return true
+}
+
+// namespaceRetainReason explains why a created namespace must be kept, or
+// returns "" when it is safe to delete
+func (r *KServeDeploymentReconciler) namespaceRetainReason(ctx context.Context, kd *platformv1alpha1.KServeDeployment, name string, shared map[string]bool) string {
+	switch {
+	case name == kd.Namespace:
+		return "it holds the KServeDeployment itself"
+	case shared[name]:
+		return "another KServeDeployment uses it"
+	}
+
+	foreign, err := r.foreignResources(ctx, kd, name)
+	if err != nil {
+		return fmt.Sprintf("could not check it for unmanaged resources: %v", err)
+	}
+	if len(foreign) == 0 {
+		return ""
+	}
+
+	reported := foreign
+	if len(reported) > maxReportedForeignResources {
+		reported = append(reported[:maxReportedForeignResources:maxReportedForeignResources],
+			fmt.Sprintf("and %d more", len(foreign)-maxReportedForeignResources))
+	}
+	return fmt.Sprintf("it contains resources the operator doesn't manage: %s", strings.Join(reported, ", "))
+}
+
+// sharedNamespaces returns the namespaces other KServeDeployments require or
+// created
+func (r *KServeDeploymentReconciler) sharedNamespaces(ctx context.Context, kd *platformv1alpha1.KServeDeployment) (map[string]bool, error) {
+	list := &platformv1alpha1.KServeDeploymentList{}
+	if err := r.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to list KServeDeployments: %w", err)
+	}
+
+	shared := map[string]bool{}
+	for i := range list.Items {
+		other := &list.Items[i]
+		if other.Namespace == kd.Namespace && other.Name == kd.Name {
+			continue
+		}
+		for _, ns := range requiredNamespaces(other) {
+			shared[ns] = true
+		}
+		for _, ns := range other.Status.CreatedNamespaces {
+			shared[ns] = true
+		}
+		shared[other.Namespace] = true
+	}
+	return shared, nil
+}
+
+// foreignResources lists the resources in namespace that this
+// KServeDeployment doesn't manage, looking at the kinds it manages and
+// checkedKinds. Resources with an owner are skipped, as garbage collection
+// removes them with their owner, and so are the ones the cluster generates
+// (the default ServiceAccount and root CA ConfigMap).
+func (r *KServeDeploymentReconciler) foreignResources(ctx context.Context, kd *platformv1alpha1.KServeDeployment, namespace string) ([]string, error) {
+	reader := r.APIReader
+	if reader == nil {
+		reader = r.Client
+	}
+
+	// Managed resources are matched regardless of version, since a kind is
+	// only listed at one
+	managed := map[schema.GroupKind]map[string]bool{}
+	kinds := map[schema.GroupKind]schema.GroupVersionKind{}
+	for _, gvk := range checkedKinds {
+		kinds[gvk.GroupKind()] = gvk
+	}
+	for _, ref := range kd.Status.ManagedResources {
+		gvk := schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind)
+		if _, ok := kinds[gvk.GroupKind()]; !ok {
+			kinds[gvk.GroupKind()] = gvk
+		}
+		if ref.Namespace != namespace {
+			continue
+		}
+		if managed[gvk.GroupKind()] == nil {
+			managed[gvk.GroupKind()] = map[string]bool{}
+		}
+		managed[gvk.GroupKind()][ref.Name] = true
+	}
+
+	foreign := []string{}
+	for gk, gvk := range kinds {
+		if generatedKinds[gk] {
+			continue
+		}
+
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := reader.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			// Kinds that aren't installed, or are cluster-scoped, can't be in
+			// the namespace
+			if meta.IsNoMatchError(err) || errors.IsNotFound(err) || errors.IsMethodNotSupported(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to list %s in %s: %w", gvk.Kind, namespace, err)
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if obj.GetNamespace() != namespace {
+				continue
+			}
+			obj.SetKind(gvk.Kind)
+			if managed[gk][obj.GetName()] || ownedBy(obj.GetLabels(), kd) || len(obj.GetOwnerReferences()) > 0 ||
+				obj.GetDeletionTimestamp() != nil || isGeneratedObject(obj) {
+				continue
+			}
+			foreign = append(foreign, fmt.Sprintf("%s %s", gvk.Kind, obj.GetName()))
+		}
+	}
+
+	sort.Strings(foreign)
+	return foreign, nil
+}
+
+// isGeneratedObject reports whether obj is one the cluster creates in every
+// namespace
+func isGeneratedObject(obj *unstructured.Unstructured) bool {
+	switch obj.GetKind() {
+	case "ServiceAccount":
+		return obj.GetName() == "default"
+	case "ConfigMap":
+		return obj.GetName() == "kube-root-ca.crt"
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// defaultComponentNamespaces are the namespaces each component's upstream
+// manifests expect. Components not listed here (kserve) use Spec.Namespace.
+var defaultComponentNamespaces = map[string]string{
+	"cert-manager": "cert-manager",
+	"knative":      "knative-serving",
+	"istio":        "istio-system",
+}
+
+// appliedComponents are the components deployComponent installs. The others
+// are accepted in the spec but skipped, so they need no namespace.
+var appliedComponents = map[string]bool{
+	"kserve":       true,
+	"cert-manager": true,
+}
+
+// upstreamNamespace returns the namespace a component's upstream manifests
+// declare their resources in
+func upstreamNamespace(component string) string {
+	if ns, ok := defaultComponentNamespaces[component]; ok {
+		return ns
+	}
+	return "kserve"
+}
+
+// componentNamespace returns the namespace a component is installed into
+func componentNamespace(kd *platformv1alpha1.KServeDeployment, component string) string {
+	if ns := kd.Spec.ComponentNamespaces[component]; ns != "" {
+		return ns
+	}
+	if ns, ok := defaultComponentNamespaces[component]; ok {
+		return ns
+	}
+	return kd.Spec.Namespace
+}
+
+// setDefaultNamespace places obj in namespace when its kind is namespaced.
+// Cluster-scoped objects are left alone, as are kinds the API server doesn't
+// know yet; applying those reports the problem.
+func (r *KServeDeploymentReconciler) setDefaultNamespace(ctx context.Context, obj *unstructured.Unstructured, namespace string) {
+	namespaced, err := r.IsObjectNamespaced(obj)
+	if err != nil {
+		log.FromContext(ctx).V(1).Info("Could not determine scope, leaving namespace unset", "kind", obj.GetKind(), "name", obj.GetName(), "error", err.Error())
+		return
+	}
+	if namespaced {
+		obj.SetNamespace(namespace)
+	}
+}
+
+// relocateNamespace returns a mutator that moves what a component's upstream
+// manifests put in from to to: resources declared there, the Namespace
+// itself, and the references to from that its bindings, webhooks, CRD
+// conversion webhooks and cert-manager CA injection carry. Resources the
+// manifests place in other namespaces (e.g. kube-system) stay there.
+func relocateNamespace(from, to string) objectMutator {
+	return func(obj *unstructured.Unstructured) error {
+		if obj.GetNamespace() == from {
+			obj.SetNamespace(to)
+		}
+
+		switch obj.GetKind() {
+		case "Namespace":
+			if obj.GetName() == from {
+				obj.SetName(to)
+			}
+		case "RoleBinding", "ClusterRoleBinding":
+			subjects, _, err := unstructured.NestedSlice(obj.Object, "subjects")
+			if err != nil {
+				return err
+			}
+			for _, subject := range subjects {
+				if s, ok := subject.(map[string]interface{}); ok && s["namespace"] == from {
+					s["namespace"] = to
+				}
+			}
+			if len(subjects) > 0 {
+				if err := unstructured.SetNestedSlice(obj.Object, subjects, "subjects"); err != nil {
+					return err
+				}
+			}
+		case "ValidatingWebhookConfiguration", "MutatingWebhookConfiguration":
+			webhooks, _, err := unstructured.NestedSlice(obj.Object, "webhooks")
+			if err != nil {
+				return err
+			}
+			for _, webhook := range webhooks {
+				if w, ok := webhook.(map[string]interface{}); ok {
+					relocateServiceRef(w, from, to, "clientConfig", "service")
+				}
+			}
+			if len(webhooks) > 0 {
+				if err := unstructured.SetNestedSlice(obj.Object, webhooks, "webhooks"); err != nil {
+					return err
+				}
+			}
+		case "CustomResourceDefinition":
+			relocateServiceRef(obj.Object, from, to, "spec", "conversion", "webhook", "clientConfig", "service")
+		}
+
+		// cert-manager's CA injector reads "<namespace>/<name>" references
+		annotations := obj.GetAnnotations()
+		for _, key := range []string{"cert-manager.io/inject-ca-from", "cert-manager.io/inject-ca-from-secret"} {
+			if ref, ok := annotations[key]; ok && strings.HasPrefix(ref, from+"/") {
+				annotations[key] = to + strings.TrimPrefix(ref, from)
+			}
+		}
+		if annotations != nil {
+			obj.SetAnnotations(annotations)
+		}
+		return nil
+	}
+}
+
+// relocateServiceRef moves the service reference at path in obj from one
+// namespace to another
+func relocateServiceRef(obj map[string]interface{}, from, to string, path ...string) {
+	if ns, found, _ := unstructured.NestedString(obj, append(path, "namespace")...); found && ns == from {
+		_ = unstructured.SetNestedField(obj, to, append(path, "namespace")...)
+	}
+}
+
+// requiredNamespaces lists the namespaces needed by the requested components
+// that are applied, in component order and without duplicates
+func requiredNamespaces(kd *platformv1alpha1.KServeDeployment) []string {
+	seen := map[string]bool{}
+	namespaces := []string{}
+
+	for _, component := range append([]string{"kserve"}, kd.Spec.Components...) {
+		if !appliedComponents[component] {
+			continue
+		}
+		ns := componentNamespace(kd, component)
+		if ns == "" || seen[ns] {
+			continue
+		}
+		seen[ns] = true
+		namespaces = append(namespaces, ns)
+	}
+
+	return namespaces
+}
+
+// ensureNamespaces creates every namespace the requested components need,
+// returning the ones that had to be created
+func (r *KServeDeploymentReconciler) ensureNamespaces(ctx context.Context, kd *platformv1alpha1.KServeDeployment) ([]string, error) {
+	// Namespaces are cluster-scoped; in single-namespace mode only the watched
+	// namespace is used and it already exists
+	if r.WatchNamespace != "" {
+		return nil, nil
+	}
+
+	created := []string{}
+	for _, ns := range requiredNamespaces(kd) {
+		ok, err := r.ensureNamespace(ctx, ns)
+		if err != nil {
+			return created, err
+		}
+		if ok {
+			created = append(created, ns)
+		}
+	}
+	return created, nil
+}
+
+// NamespaceTerminatingError is returned while a required namespace is still
+// being deleted and so can't be recreated yet
+type NamespaceTerminatingError struct {
+	Namespace string
+}
+
+func (e *NamespaceTerminatingError) Error() string {
+	return fmt.Sprintf("namespace %s is being deleted", e.Namespace)
+}
+
+// asNamespaceTerminating reports whether err was caused by a namespace that
+// is still being deleted
+func asNamespaceTerminating(err error) (*NamespaceTerminatingError, bool) {
+	var terminating *NamespaceTerminatingError
+	ok := stderrors.As(err, &terminating)
+	return terminating, ok
+}
+
+// ensureNamespace creates the namespace if it doesn't exist and reports
+// whether it did so
+func (r *KServeDeploymentReconciler) ensureNamespace(ctx context.Context, name string) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, client.ObjectKey{Name: name}, ns); err == nil {
+		if ns.DeletionTimestamp != nil {
+			return false, &NamespaceTerminatingError{Namespace: name}
+		}
+		return false, nil
+	} else if !errors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to get namespace %s: %w", name, err)
+	}
+
+	logger.Info("Creating namespace", "namespace", name)
+	ns = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if err := r.Create(ctx, ns); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create namespace %s: %w", name, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+func TestRelocateNamespaceMovesWhatUpstreamPutsInIt(t *testing.T) {
+	relocate := relocateNamespace("cert-manager", "certs")
+
+	for _, tc := range []struct {
+		name     string
+		manifest string
+		field    []string
+		want     string
+	}{
+		{
+			name:     "declared namespace",
+			manifest: "apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: cert-manager\n  namespace: cert-manager\n",
+			field:    []string{"metadata", "namespace"},
+			want:     "certs",
+		},
+		{
+			name:     "other namespace",
+			manifest: "apiVersion: rbac.authorization.k8s.io/v1\nkind: Role\nmetadata:\n  name: leader-election\n  namespace: kube-system\n",
+			field:    []string{"metadata", "namespace"},
+			want:     "kube-system",
+		},
+		{
+			name:     "namespace object",
+			manifest: "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: cert-manager\n",
+			field:    []string{"metadata", "name"},
+			want:     "certs",
+		},
+		{
+			name:     "webhook service",
+			manifest: "apiVersion: admissionregistration.k8s.io/v1\nkind: ValidatingWebhookConfiguration\nmetadata:\n  name: cert-manager-webhook\n  annotations:\n    cert-manager.io/inject-ca-from-secret: cert-manager/cert-manager-webhook-ca\nwebhooks:\n- name: webhook.cert-manager.io\n  clientConfig:\n    service:\n      name: cert-manager-webhook\n      namespace: cert-manager\n",
+			field:    []string{"metadata", "annotations", "cert-manager.io/inject-ca-from-secret"},
+			want:     "certs/cert-manager-webhook-ca",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{}
+			if err := yaml.Unmarshal([]byte(tc.manifest), &obj.Object); err != nil {
+				t.Fatal(err)
+			}
+			if err := relocate(obj); err != nil {
+				t.Fatal(err)
+			}
+			if got, _, _ := unstructured.NestedString(obj.Object, tc.field...); got != tc.want {
+				t.Fatalf("%v = %q, want %q", tc.field, got, tc.want)
+			}
+			if obj.GetKind() == "ValidatingWebhookConfiguration" {
+				webhooks, _, _ := unstructured.NestedSlice(obj.Object, "webhooks")
+				ns, _, _ := unstructured.NestedString(webhooks[0].(map[string]interface{}), "clientConfig", "service", "namespace")
+				if ns != "certs" {
+					t.Fatalf("webhook service namespace = %q, want certs", ns)
+				}
+			}
+		})
+	}
+}
+
+func TestRequiredNamespacesSkipComponentsThatArentApplied(t *testing.T) {
+	kd := &platformv1alpha1.KServeDeployment{Spec: platformv1alpha1.KServeDeploymentSpec{
+		Namespace:  "kserve",
+		Components: []string{"knative", "cert-manager", "istio", "kserve"},
+	}}
+
+	want := []string{"kserve", "cert-manager"}
+	if got := requiredNamespaces(kd); !reflect.DeepEqual(got, want) {
+		t.Errorf("requiredNamespaces = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// globallyPaused reports whether the pause ConfigMap exists. While it does,
+// every reconcile stops before touching the cluster.
+func (r *KServeDeploymentReconciler) globallyPaused(ctx context.Context) (bool, error) {
+	if r.PauseConfigMap.Name == "" {
+		return false, nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	err := r.Get(ctx, r.PauseConfigMap, cm)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read pause ConfigMap %s: %w", r.PauseConfigMap, err)
+	}
+	return true, nil
+}
+
+// reportPaused sets the GloballyPaused condition, leaving the phase and
+// everything else in status as the last reconcile left it
+func (r *KServeDeploymentReconciler) reportPaused(ctx context.Context, kd *platformv1alpha1.KServeDeployment) error {
+	message := fmt.Sprintf("Reconciles are paused until ConfigMap %s is deleted", r.PauseConfigMap)
+	if existing := meta.FindStatusCondition(kd.Status.Conditions, "GloballyPaused"); existing != nil &&
+		existing.Status == metav1.ConditionTrue && existing.ObservedGeneration == kd.Generation && existing.Message == message {
+		return nil
+	}
+
+	meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+		Type:               "GloballyPaused",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kd.Generation,
+		Reason:             platformv1alpha1.ReasonPauseConfigMapPresent,
+		Message:            message,
+	})
+	return r.Status().Update(ctx, kd)
+}
+
+// isPauseConfigMap reports whether key names the pause ConfigMap
+func (r *KServeDeploymentReconciler) isPauseConfigMap(key types.NamespacedName) bool {
+	return r.PauseConfigMap.Name != "" && key == r.PauseConfigMap
+}
+
+// pauseConfigMapSource watches the pause ConfigMap through a cache holding
+// only that ConfigMap, since the manager's cache only holds the ConfigMaps
+// labeled with watchLabel
+func (r *KServeDeploymentReconciler) pauseConfigMapSource(mgr ctrl.Manager) (source.Source, error) {
+	pauseCache, err := cache.New(mgr.GetConfig(), cache.Options{
+		HTTPClient:           mgr.GetHTTPClient(),
+		Scheme:               mgr.GetScheme(),
+		Mapper:               mgr.GetRESTMapper(),
+		DefaultNamespaces:    map[string]cache.Config{r.PauseConfigMap.Namespace: {}},
+		DefaultFieldSelector: fields.OneTermEqualSelector("metadata.name", r.PauseConfigMap.Name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the pause ConfigMap cache: %w", err)
+	}
+	if err := mgr.Add(pauseCache); err != nil {
+		return nil, err
+	}
+	return source.Kind(pauseCache, &corev1.ConfigMap{}), nil
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// hasPlacement reports whether the spec overrides where components run
+func hasPlacement(kd *platformv1alpha1.KServeDeployment) bool {
+	return len(kd.Spec.NodeSelector) > 0 || len(kd.Spec.Tolerations) > 0 || kd.Spec.Affinity != nil
+}
+
+// placeComponentPods returns a mutator that applies the spec's node selector,
+// tolerations and affinity to the pod template of component Deployments
+func placeComponentPods(kd *platformv1alpha1.KServeDeployment) objectMutator {
+	nodeSelector := kd.Spec.NodeSelector
+	tolerations := kd.Spec.Tolerations
+	affinity := kd.Spec.Affinity
+
+	return func(obj *unstructured.Unstructured) error {
+		if obj.GetKind() != "Deployment" {
+			return nil
+		}
+		podSpec := []string{"spec", "template", "spec"}
+
+		if len(nodeSelector) > 0 {
+			merged, _, err := unstructured.NestedStringMap(obj.Object, append(podSpec, "nodeSelector")...)
+			if err != nil {
+				return fmt.Errorf("failed to read nodeSelector: %w", err)
+			}
+			if merged == nil {
+				merged = map[string]string{}
+			}
+			for k, v := range nodeSelector {
+				merged[k] = v
+			}
+			if err := unstructured.SetNestedStringMap(obj.Object, merged, append(podSpec, "nodeSelector")...); err != nil {
+				return err
+			}
+		}
+
+		if len(tolerations) > 0 {
+			existing, _, err := unstructured.NestedSlice(obj.Object, append(podSpec, "tolerations")...)
+			if err != nil {
+				return fmt.Errorf("failed to read tolerations: %w", err)
+			}
+			for i := range tolerations {
+				toleration, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&tolerations[i])
+				if err != nil {
+					return fmt.Errorf("failed to convert toleration: %w", err)
+				}
+				existing = append(existing, toleration)
+			}
+			if err := unstructured.SetNestedSlice(obj.Object, existing, append(podSpec, "tolerations")...); err != nil {
+				return err
+			}
+		}
+
+		if affinity != nil {
+			converted, err := runtime.DefaultUnstructuredConverter.ToUnstructured(affinity)
+			if err != nil {
+				return fmt.Errorf("failed to convert affinity: %w", err)
+			}
+			if err := unstructured.SetNestedMap(obj.Object, converted, append(podSpec, "affinity")...); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// setPriorityClass returns a mutator that gives the pods of every workload
+// the named PriorityClass. A fixed priority in the manifest is dropped, since
+// admission rejects one that doesn't match the class.
+func setPriorityClass(name string) objectMutator {
+	return func(obj *unstructured.Unstructured) error {
+		podSpec, ok := podSpecPaths[obj.GetKind()]
+		if !ok {
+			return nil
+		}
+		unstructured.RemoveNestedField(obj.Object, append(append([]string{}, podSpec...), "priority")...)
+		return unstructured.SetNestedField(obj.Object, name, append(append([]string{}, podSpec...), "priorityClassName")...)
+	}
+}
+
+// checkPriorityClass fails when the requested PriorityClass doesn't exist,
+// which would otherwise leave the control-plane pods unable to be created
+func (r *KServeDeploymentReconciler) checkPriorityClass(ctx context.Context, kd *platformv1alpha1.KServeDeployment) error {
+	if kd.Spec.PriorityClassName == "" {
+		return nil
+	}
+
+	class := &schedulingv1.PriorityClass{}
+	if err := r.Get(ctx, client.ObjectKey{Name: kd.Spec.PriorityClassName}, class); err != nil {
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("PriorityClass %s does not exist", kd.Spec.PriorityClassName)
+		}
+		return fmt.Errorf("failed to get PriorityClass %s: %w", kd.Spec.PriorityClassName, err)
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+const (
+	// postInstallJobPollInterval is how often running post-install Jobs are checked
+	postInstallJobPollInterval = 10 * time.Second
+
+	// defaultPostInstallJobTimeout applies when a Job doesn't set TimeoutSeconds
+	defaultPostInstallJobTimeout int64 = 600
+
+	// Labels stamped on post-install Jobs so they can be traced to their owner
+	ownerNameLabel      = "platform.ai-platform.io/kservedeployment"
+	ownerNamespaceLabel = "platform.ai-platform.io/kservedeployment-namespace"
+
+	// postInstallJobHashAnnotation records the version and Job spec a Job runs for
+	postInstallJobHashAnnotation = "platform.ai-platform.io/job-hash"
+)
+
+// reconcilePostInstallJobs creates each post-install Job once per version and
+// Job spec and records its outcome in status. It returns true while any Job
+// is still running, and an error describing the first failed Job.
+func (r *KServeDeploymentReconciler) reconcilePostInstallJobs(ctx context.Context, kd *platformv1alpha1.KServeDeployment) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	pending := false
+	var failure error
+	statuses := []platformv1alpha1.JobStatus{}
+
+	for _, spec := range kd.Spec.PostInstallJobs {
+		status := findJobStatus(kd.Status.PostInstallJobs, spec.Name)
+		key := client.ObjectKey{Namespace: componentNamespace(kd, "kserve"), Name: postInstallJobName(kd, spec.Name)}
+		hash := postInstallJobHash(kd, spec)
+
+		// Outcomes recorded before Jobs were hashed count for the current spec
+		if status.Phase != "" && status.Hash == "" {
+			status.Hash = hash
+		}
+
+		// Jobs only run once; a finished Job keeps its recorded outcome
+		// until the version or its spec changes
+		if (status.Phase == "Succeeded" || status.Phase == "Failed") && status.Hash == hash {
+			statuses = append(statuses, status)
+			if status.Phase == "Failed" && failure == nil {
+				failure = fmt.Errorf("post-install Job %s failed: %s", spec.Name, status.Message)
+			}
+			if retainsJob(spec, status.Phase) {
+				recordApplied(ctx, postInstallJobRef(key))
+			}
+			continue
+		}
+
+		job := &batchv1.Job{}
+		if err := r.Get(ctx, key, job); err != nil {
+			if !errors.IsNotFound(err) {
+				return pending, fmt.Errorf("failed to get post-install Job %s: %w", key.Name, err)
+			}
+
+			logger.Info("Creating post-install Job", "job", key.Name, "namespace", key.Namespace)
+			job = newPostInstallJob(kd, spec, key, hash)
+			if err := r.setLocalOwner(kd, job); err != nil {
+				return pending, err
+			}
+			if err := r.Create(ctx, job); err != nil {
+				return pending, fmt.Errorf("failed to create post-install Job %s: %w", key.Name, err)
+			}
+			recordApplied(ctx, postInstallJobRef(key))
+
+			statuses = append(statuses, platformv1alpha1.JobStatus{
+				Name:    spec.Name,
+				Phase:   "Running",
+				Message: "Job created",
+				LogsRef: jobLogsRef(key),
+				Hash:    hash,
+			})
+			pending = true
+			continue
+		}
+		recordApplied(ctx, postInstallJobRef(key))
+
+		// A Job left over from another version or spec is replaced; the new
+		// one is created once the old one is gone
+		if jobHash := job.Annotations[postInstallJobHashAnnotation]; jobHash != "" && jobHash != hash {
+			if job.DeletionTimestamp.IsZero() {
+				logger.Info("Replacing post-install Job for a changed version or spec", "job", key.Name)
+				propagation := metav1.DeletePropagationBackground
+				if err := r.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !errors.IsNotFound(err) {
+					return pending, fmt.Errorf("failed to delete outdated post-install Job %s: %w", key.Name, err)
+				}
+			}
+			statuses = append(statuses, platformv1alpha1.JobStatus{
+				Name:    spec.Name,
+				Phase:   "Running",
+				Message: "Replacing the Job of a previous version or spec",
+				LogsRef: jobLogsRef(key),
+				Hash:    hash,
+			})
+			pending = true
+			continue
+		}
+
+		status = jobStatusFor(spec.Name, job)
+		status.Hash = hash
+		statuses = append(statuses, status)
+
+		switch status.Phase {
+		case "Running":
+			pending = true
+			continue
+		case "Failed":
+			logger.Info("Post-install Job failed", "job", key.Name, "message", status.Message)
+			if failure == nil {
+				failure = fmt.Errorf("post-install Job %s failed: %s", spec.Name, status.Message)
+			}
+		default:
+			logger.Info("Post-install Job succeeded", "job", key.Name)
+		}
+
+		if err := r.cleanupPostInstallJob(ctx, spec, job, status.Phase); err != nil {
+			logger.Error(err, "Failed to clean up post-install Job", "job", key.Name)
+		}
+	}
+
+	kd.Status.PostInstallJobs = statuses
+	return pending, failure
+}
+
+// cleanupPostInstallJob deletes a finished Job according to its retention policy
+func (r *KServeDeploymentReconciler) cleanupPostInstallJob(ctx context.Context, spec platformv1alpha1.JobSpec, job *batchv1.Job, phase string) error {
+	if retainsJob(spec, phase) {
+		return nil
+	}
+
+	propagation := metav1.DeletePropagationBackground
+	if err := r.Delete(ctx, job, &client.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// retainsJob reports whether the retention policy keeps a Job that finished in phase
+func retainsJob(spec platformv1alpha1.JobSpec, phase string) bool {
+	switch spec.RetentionPolicy {
+	case "Retain":
+		return true
+	case "Delete":
+		return false
+	default:
+		// DeleteOnSuccess keeps failed Jobs around so their logs can be inspected
+		return phase != "Succeeded"
+	}
+}
+
+// postInstallJobHash identifies the version and Job spec a Job runs for
+func postInstallJobHash(kd *platformv1alpha1.KServeDeployment, spec platformv1alpha1.JobSpec) string {
+	data, err := json.Marshal(struct {
+		Version string                   `json:"version"`
+		Job     platformv1alpha1.JobSpec `json:"job"`
+	}{kd.Spec.Version, spec})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func postInstallJobRef(key client.ObjectKey) platformv1alpha1.ResourceRef {
+	return platformv1alpha1.ResourceRef{APIVersion: "batch/v1", Kind: "Job", Namespace: key.Namespace, Name: key.Name}
+}
+
+func newPostInstallJob(kd *platformv1alpha1.KServeDeployment, spec platformv1alpha1.JobSpec, key client.ObjectKey, hash string) *batchv1.Job {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Labels: map[string]string{
+				ownerNameLabel:      kd.Name,
+				ownerNamespaceLabel: kd.Namespace,
+			},
+			Annotations: map[string]string{
+				postInstallJobHashAnnotation: hash,
+			},
+		},
+		Spec: *spec.Spec.DeepCopy(),
+	}
+
+	// The timeout is enforced by Kubernetes so a hung Job ends up Failed
+	if job.Spec.ActiveDeadlineSeconds == nil {
+		timeout := defaultPostInstallJobTimeout
+		if spec.TimeoutSeconds != nil {
+			timeout = *spec.TimeoutSeconds
+		}
+		job.Spec.ActiveDeadlineSeconds = &timeout
+	}
+
+	if job.Spec.Template.Spec.RestartPolicy == "" {
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+
+	return job
+}
+
+// jobStatusFor derives the recorded status from a live Job
+func jobStatusFor(name string, job *batchv1.Job) platformv1alpha1.JobStatus {
+	status := platformv1alpha1.JobStatus{
+		Name:    name,
+		Phase:   "Running",
+		Message: fmt.Sprintf("%d active, %d succeeded, %d failed", job.Status.Active, job.Status.Succeeded, job.Status.Failed),
+		LogsRef: jobLogsRef(client.ObjectKeyFromObject(job)),
+	}
+
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			status.Phase = "Succeeded"
+			status.Message = "Job completed"
+		case batchv1.JobFailed:
+			status.Phase = "Failed"
+			status.Message = fmt.Sprintf("%s: %s", c.Reason, c.Message)
+		default:
+			continue
+		}
+		completed := c.LastTransitionTime
+		status.CompletionTime = &completed
+	}
+
+	return status
+}
+
+func findJobStatus(statuses []platformv1alpha1.JobStatus, name string) platformv1alpha1.JobStatus {
+	for _, s := range statuses {
+		if s.Name == name {
+			return s
+		}
+	}
+	return platformv1alpha1.JobStatus{Name: name}
+}
+
+func postInstallJobName(kd *platformv1alpha1.KServeDeployment, name string) string {
+	return fmt.Sprintf("%s-%s", kd.Name, name)
+}
+
+func jobLogsRef(key client.ObjectKey) string {
+	return fmt.Sprintf("kubectl logs -n %s job/%s", key.Namespace, key.Name)
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+func TestPostInstallJobRunsAgainForANewVersion(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := platformv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := platformv1alpha1.JobSpec{
+		Name:            "warmup",
+		RetentionPolicy: "Retain",
+		Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "warmup", Image: "curlimages/curl:latest"}},
+		}}},
+	}
+	kd := &platformv1alpha1.KServeDeployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "kserve-minimal", Namespace: "kserve", UID: "kd-uid"},
+		Spec: platformv1alpha1.KServeDeploymentSpec{
+			Version:         "v0.11.0",
+			Namespace:       "kserve",
+			PostInstallJobs: []platformv1alpha1.JobSpec{spec},
+		},
+	}
+	key := client.ObjectKey{Namespace: "kserve", Name: postInstallJobName(kd, "warmup")}
+	previousHash := postInstallJobHash(kd, spec)
+	kd.Spec.Version = "v0.12.0"
+
+	// The Job failed on the previous version and was retained
+	previous := newPostInstallJob(kd, spec, key, previousHash)
+	kd.Status.PostInstallJobs = []platformv1alpha1.JobStatus{{
+		Name: "warmup", Phase: "Failed", Message: "BackoffLimitExceeded: Job has reached the specified backoff limit", Hash: previousHash,
+	}}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(previous).Build()
+	r := &KServeDeploymentReconciler{Client: c, Scheme: scheme}
+	ctx := withReconcileState(context.Background(), time.Now())
+
+	pending, err := r.reconcilePostInstallJobs(ctx, kd)
+	if err != nil || !pending {
+		t.Fatalf("first reconcile = %v, %v; want pending and no error", pending, err)
+	}
+	if err := c.Get(ctx, key, &batchv1.Job{}); !errors.IsNotFound(err) {
+		t.Fatalf("the previous version's Job wasn't deleted: %v", err)
+	}
+
+	pending, err = r.reconcilePostInstallJobs(ctx, kd)
+	if err != nil || !pending {
+		t.Fatalf("second reconcile = %v, %v; want pending and no error", pending, err)
+	}
+	job := &batchv1.Job{}
+	if err := c.Get(ctx, key, job); err != nil {
+		t.Fatalf("the Job wasn't created again: %v", err)
+	}
+	hash := postInstallJobHash(kd, spec)
+	if job.Annotations[postInstallJobHashAnnotation] != hash {
+		t.Errorf("Job %s = %q, want %q", postInstallJobHashAnnotation, job.Annotations[postInstallJobHashAnnotation], hash)
+	}
+	if owner := metav1.GetControllerOf(job); owner == nil || owner.UID != kd.UID {
+		t.Errorf("Job owner = %v, want %s", owner, kd.Name)
+	}
+	if status := kd.Status.PostInstallJobs[0]; status.Phase != "Running" || status.Hash != hash {
+		t.Errorf("status = %+v, want Running for the new hash", status)
+	}
+
+	ref := postInstallJobRef(key)
+	tracked := false
+	for _, applied := range reconcileStateFrom(ctx).applied {
+		tracked = tracked || applied == ref
+	}
+	if !tracked {
+		t.Errorf("Job %s isn't tracked in ManagedResources", key)
+	}
+}
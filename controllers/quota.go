@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// checkResourceQuotas fails early, with an InsufficientQuota condition
+// listing the shortfall, when the workloads about to be created don't fit
+// the ResourceQuotas of their namespaces. Workloads that already exist are
+// already counted in the quota's usage and are skipped. Nothing is rendered
+// when the namespaces have no quotas.
+func (r *KServeDeploymentReconciler) checkResourceQuotas(ctx context.Context, kd *platformv1alpha1.KServeDeployment) error {
+	quotas := map[string][]corev1.ResourceQuota{}
+	for _, ns := range append(requiredNamespaces(kd), kd.Namespace) {
+		if _, ok := quotas[ns]; ok {
+			continue
+		}
+		list := &corev1.ResourceQuotaList{}
+		if err := r.List(ctx, list, client.InNamespace(ns)); err != nil {
+			return fmt.Errorf("failed to list resource quotas in %s: %w", ns, err)
+		}
+		quotas[ns] = list.Items
+	}
+	if !anyQuotas(quotas) {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "InsufficientQuota")
+		return nil
+	}
+
+	rendered, err := r.renderObjects(ctx, kd)
+	if err != nil {
+		// The install itself reports why the manifests can't be applied
+		log.FromContext(ctx).V(1).Info("Skipping quota check, manifests did not render", "error", err.Error())
+		return nil
+	}
+
+	needed := map[string]corev1.ResourceList{}
+	for i := range rendered {
+		obj := &rendered[i]
+		if len(quotas[obj.GetNamespace()]) == 0 {
+			continue
+		}
+		usage, ok, err := workloadUsage(obj)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		exists, err := r.objectExists(ctx, obj)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		addResources(needed, obj.GetNamespace(), usage)
+	}
+
+	shortfalls := []string{}
+	for ns, usage := range needed {
+		for _, quota := range quotas[ns] {
+			shortfalls = append(shortfalls, quotaShortfalls(&quota, usage)...)
+		}
+	}
+	if len(shortfalls) == 0 {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "InsufficientQuota")
+		return nil
+	}
+
+	sort.Strings(shortfalls)
+	message := "insufficient resource quota: " + strings.Join(shortfalls, "; ")
+	meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+		Type:               "InsufficientQuota",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kd.Generation,
+		Reason:             platformv1alpha1.ReasonQuotaExceeded,
+		Message:            message,
+	})
+	return fmt.Errorf("%s", message)
+}
+
+// workloadUsage returns the quota a workload's pods consume, as quota
+// resource names (requests.cpu, limits.memory, pods, ...). ok is false for
+// objects that don't run pods.
+func workloadUsage(obj *unstructured.Unstructured) (corev1.ResourceList, bool, error) {
+	path, ok := podSpecPaths[obj.GetKind()]
+	if !ok {
+		return nil, false, nil
+	}
+	raw, found, err := unstructured.NestedMap(obj.Object, path...)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	podSpec := &corev1.PodSpec{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, podSpec); err != nil {
+		return nil, false, fmt.Errorf("failed to read pod spec of %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	replicas := int64(1)
+	switch obj.GetKind() {
+	case "Deployment", "StatefulSet", "ReplicaSet":
+		if n, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); found {
+			replicas = n
+		}
+	case "Job":
+		if n, found, _ := unstructured.NestedInt64(obj.Object, "spec", "parallelism"); found {
+			replicas = n
+		}
+	}
+
+	usage := corev1.ResourceList{}
+	pod := podResources(podSpec)
+	for name, quantity := range pod.Requests {
+		addQuantity(usage, corev1.ResourceName("requests."+string(name)), quantity, replicas)
+		addQuantity(usage, name, quantity, replicas)
+	}
+	for name, quantity := range pod.Limits {
+		addQuantity(usage, corev1.ResourceName("limits."+string(name)), quantity, replicas)
+	}
+	addQuantity(usage, corev1.ResourcePods, *resource.NewQuantity(1, resource.DecimalSI), replicas)
+	return usage, true, nil
+}
+
+// podResources returns the effective requests and limits of a pod: the sum
+// of its containers, or its largest init container when that is more
+func podResources(spec *corev1.PodSpec) corev1.ResourceRequirements {
+	total := corev1.ResourceRequirements{Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{}}
+	for _, c := range spec.Containers {
+		for name, quantity := range c.Resources.Requests {
+			addQuantity(total.Requests, name, quantity, 1)
+		}
+		for name, quantity := range c.Resources.Limits {
+			addQuantity(total.Limits, name, quantity, 1)
+		}
+	}
+	for _, c := range spec.InitContainers {
+		maxQuantities(total.Requests, c.Resources.Requests)
+		maxQuantities(total.Limits, c.Resources.Limits)
+	}
+	return total
+}
+
+// quotaShortfalls describes each resource of quota that usage doesn't fit in
+func quotaShortfalls(quota *corev1.ResourceQuota, usage corev1.ResourceList) []string {
+	shortfalls := []string{}
+	for name, hard := range quota.Status.Hard {
+		needed, ok := usage[name]
+		if !ok {
+			continue
+		}
+		remaining := hard.DeepCopy()
+		if used, ok := quota.Status.Used[name]; ok {
+			remaining.Sub(used)
+		}
+		if needed.Cmp(remaining) > 0 {
+			shortfalls = append(shortfalls, fmt.Sprintf("%s/%s: %s needs %s, %s left",
+				quota.Namespace, quota.Name, name, needed.String(), remaining.String()))
+		}
+	}
+	return shortfalls
+}
+
+// objectExists reports whether obj is already in the cluster
+func (r *KServeDeploymentReconciler) objectExists(ctx context.Context, obj *unstructured.Unstructured) (bool, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+	err := r.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	return true, nil
+}
+
+func anyQuotas(quotas map[string][]corev1.ResourceQuota) bool {
+	for _, list := range quotas {
+		if len(list) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func addResources(totals map[string]corev1.ResourceList, namespace string, usage corev1.ResourceList) {
+	if totals[namespace] == nil {
+		totals[namespace] = corev1.ResourceList{}
+	}
+	for name, quantity := range usage {
+		addQuantity(totals[namespace], name, quantity, 1)
+	}
+}
+
+// addQuantity adds times copies of quantity to list[name]
+func addQuantity(list corev1.ResourceList, name corev1.ResourceName, quantity resource.Quantity, times int64) {
+	total := list[name]
+	for i := int64(0); i < times; i++ {
+		total.Add(quantity)
+	}
+	list[name] = total
+}
+
+// maxQuantities raises each entry of list to the one in other when that is larger
+func maxQuantities(list, other corev1.ResourceList) {
+	for name, quantity := range other {
+		if current, ok := list[name]; !ok || quantity.Cmp(current) > 0 {
+			list[name] = quantity.DeepCopy()
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// reapplyAnnotation lists components (comma separated) to force-reapply,
+// e.g. "kserve". The operator re-deploys only those, ignoring the unchanged
+// content-hash skip, then removes the annotation.
+const reapplyAnnotation = "platform.ai-platform.io/reapply"
+
+// requestedReapply returns the components listed in the reapply annotation,
+// rejecting any that aren't part of the spec
+func requestedReapply(kd *platformv1alpha1.KServeDeployment) ([]string, error) {
+	value := strings.TrimSpace(kd.Annotations[reapplyAnnotation])
+	if value == "" {
+		return nil, nil
+	}
+
+	components := []string{}
+	for _, component := range strings.Split(value, ",") {
+		component = strings.TrimSpace(component)
+		if component == "" {
+			continue
+		}
+		if !hasComponent(kd, component) {
+			return nil, fmt.Errorf("%s annotation names component %s, which is not in spec.components", reapplyAnnotation, component)
+		}
+		components = append(components, component)
+	}
+	return components, nil
+}
+
+// reapplyComponents force-reapplies the given components and leaves every
+// other component untouched. The annotation is only removed once all of them
+// were applied, so a failed repair is retried.
+func (r *KServeDeploymentReconciler) reapplyComponents(ctx context.Context, kd *platformv1alpha1.KServeDeployment, components []string) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if state := reconcileStateFrom(ctx); state != nil {
+		state.forceApply = true
+	}
+
+	for _, component := range components {
+		logger.Info("Force-reapplying component", "component", component)
+		changedBefore := changedCount(ctx)
+		if err := r.deployComponentAtomically(ctx, kd, component); err != nil {
+			if shuttingDown(ctx) {
+				return r.abandonReconcile(ctx)
+			}
+			logger.Error(err, "Failed to reapply component", "component", component)
+			return r.markFailed(ctx, kd, kd.Status.InstalledComponents, withReason(platformv1alpha1.ReasonApplyFailed, err))
+		}
+		r.setComponentStatus(kd, component, changedCount(ctx) > changedBefore)
+	}
+
+	r.recordEvent(kd, corev1.EventTypeNormal, "ComponentsReapplied",
+		fmt.Sprintf("Force-reapplied %s", strings.Join(components, ", ")))
+
+	// Removing the annotation triggers a regular reconcile, which records the
+	// component statuses and recomputes the phase
+	patch := client.MergeFrom(kd.DeepCopy())
+	delete(kd.Annotations, reapplyAnnotation)
+	if err := r.Patch(ctx, kd, patch); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to clear %s annotation: %w", reapplyAnnotation, err)
+	}
+	return ctrl.Result{}, nil
+}
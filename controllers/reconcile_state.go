@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// reconcileState carries what a single reconcile learns along the way (for
+// example conflicts hit deep in the apply path) back up to the status update
+type reconcileState struct {
+	// start is when the reconcile began
+	start time.Time
+
+	// fieldConflicts lists server-side apply conflicts, one entry per resource
+	fieldConflicts []string
+
+	// slowSources describes manifest fetches that were unusually slow
+	slowSources []string
+
+	// applied lists the resources applied so far, in apply order
+	applied []platformv1alpha1.ResourceRef
+
+	// failed lists resources that could not be applied
+	failed []platformv1alpha1.ResourceRef
+
+	// created lists the applied resources that did not exist before
+	created []platformv1alpha1.ResourceRef
+
+	// changed counts the applied resources that were created or modified,
+	// rather than skipped as unchanged
+	changed int
+
+	// appliedObjects are the applied resources as they were sent, in the
+	// form render returns them, when applyOptions.keepApplied asks for them
+	appliedObjects []unstructured.Unstructured
+
+	// retriesDenied counts resources left unretried by the retry budget
+	retriesDenied int
+
+	// forceApply updates resources even when their content hash is unchanged
+	forceApply bool
+
+	// render collects the objects that would be applied in rendered instead
+	// of applying them
+	render   bool
+	rendered []unstructured.Unstructured
+
+	// renderCache holds renderObjects' result, so the checks run before the
+	// install and the rendered manifests share one render per reconcile
+	renderCache *renderResult
+}
+
+// renderResult is the outcome of a renderObjects call
+type renderResult struct {
+	objects []unstructured.Unstructured
+	err     error
+}
+
+type reconcileStateKey struct{}
+
+// withReconcileState attaches fresh per-reconcile state to ctx
+func withReconcileState(ctx context.Context, start time.Time) context.Context {
+	return context.WithValue(ctx, reconcileStateKey{}, &reconcileState{start: start})
+}
+
+// reconcileStateFrom returns the state of the current reconcile, or nil when
+// called outside of one
+func reconcileStateFrom(ctx context.Context) *reconcileState {
+	state, _ := ctx.Value(reconcileStateKey{}).(*reconcileState)
+	return state
+}
@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// Field indexes over the objects a KServeDeployment references, keyed by
+// "namespace/name", so a change to one maps straight to its users
+const (
+	configMapRefIndex       = "spec.extraManifests.configMapRef"
+	imagePullSecretRefIndex = "spec.imagePullSecrets"
+)
+
+// watchLabel opts a referenced ConfigMap or Secret into the operator's watch,
+// so editing it is acted on straight away rather than on the next reconcile.
+// The copies the operator makes of Secrets carry it too.
+const watchLabel = "platform.ai-platform.io/watch"
+
+// CacheByObject restricts the ConfigMap and Secret informers to the objects
+// labeled with watchLabel, so the operator doesn't cache every ConfigMap and
+// Secret in the cluster. Both are read from the API server instead; see
+// UncachedObjects. The pause ConfigMap is watched through a cache of its own.
+func CacheByObject() map[client.Object]cache.ByObject {
+	watched := labels.SelectorFromSet(labels.Set{watchLabel: "true"})
+	return map[client.Object]cache.ByObject{
+		&corev1.ConfigMap{}: {Label: watched},
+		&corev1.Secret{}:    {Label: watched},
+	}
+}
+
+// UncachedObjects are read straight from the API server. ConfigMaps and
+// Secrets, because the informers only hold the labeled ones, and Pods, which
+// are only read in a few namespaces and would otherwise be cached
+// cluster-wide.
+func UncachedObjects() []client.Object {
+	return []client.Object{&corev1.ConfigMap{}, &corev1.Secret{}, &corev1.Pod{}}
+}
+
+// indexReferences registers the reference indexes with the manager's cache
+func indexReferences(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &platformv1alpha1.KServeDeployment{}, configMapRefIndex, func(obj client.Object) []string {
+		kd := obj.(*platformv1alpha1.KServeDeployment)
+		keys := []string{}
+		for _, ref := range kd.Spec.ExtraManifests {
+			if ref.ConfigMapRef != nil {
+				keys = append(keys, configMapRefKey(kd, ref.ConfigMapRef).String())
+			}
+		}
+		return keys
+	}); err != nil {
+		return err
+	}
+
+	return indexer.IndexField(ctx, &platformv1alpha1.KServeDeployment{}, imagePullSecretRefIndex, func(obj client.Object) []string {
+		kd := obj.(*platformv1alpha1.KServeDeployment)
+		keys := []string{}
+		for _, name := range kd.Spec.ImagePullSecrets {
+			keys = append(keys, client.ObjectKey{Namespace: kd.Namespace, Name: name}.String())
+		}
+		return keys
+	})
+}
+
+// requestsForSecret maps a pull secret to the KServeDeployments that use it,
+// and a copy made by the operator to the KServeDeployment it was made for, so
+// rotated credentials are copied and deleted copies restored right away
+func (r *KServeDeploymentReconciler) requestsForSecret(ctx context.Context, obj client.Object) []reconcile.Request {
+	requests := r.requestsForIndex(ctx, imagePullSecretRefIndex, client.ObjectKeyFromObject(obj))
+
+	labels := obj.GetLabels()
+	if name, namespace := labels[ownerNameLabel], labels[ownerNamespaceLabel]; name != "" && namespace != "" {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKey{Namespace: namespace, Name: name}})
+	}
+	return requests
+}
+
+// requestsForIndex returns a request for each KServeDeployment whose index
+// entry matches key
+func (r *KServeDeploymentReconciler) requestsForIndex(ctx context.Context, index string, key client.ObjectKey) []reconcile.Request {
+	list := &platformv1alpha1.KServeDeploymentList{}
+	if err := r.List(ctx, list, client.MatchingFields{index: key.String()}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list KServeDeployments by reference", "index", index, "key", key)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for i := range list.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+	}
+	return requests
+}
@@ -0,0 +1,73 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+func TestConfigMapEventsMapThroughTheReferenceIndex(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := platformv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	user := &platformv1alpha1.KServeDeployment{ObjectMeta: metav1.ObjectMeta{Name: "user", Namespace: "platform"}}
+	user.Spec.ExtraManifests = []platformv1alpha1.ManifestRef{{
+		Name:         "gateway",
+		ConfigMapRef: &platformv1alpha1.ConfigMapKeyReference{Name: "platform-manifests", Key: "gateway.yaml"},
+	}}
+	other := &platformv1alpha1.KServeDeployment{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "platform"}}
+
+	builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(user, other)
+	if err := indexReferences(context.Background(), indexerFunc(func(obj client.Object, field string, extract client.IndexerFunc) {
+		builder = builder.WithIndex(obj, field, extract)
+	})); err != nil {
+		t.Fatal(err)
+	}
+	r := &KServeDeploymentReconciler{
+		Client:         builder.Build(),
+		PauseConfigMap: types.NamespacedName{Namespace: "ai-platform-system", Name: "ai-platform-operator-pause"},
+	}
+
+	labeled := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "platform-manifests", Namespace: "platform", Labels: map[string]string{watchLabel: "true"},
+	}}
+	unlabeled := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "platform-manifests", Namespace: "platform"}}
+	pause := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "ai-platform-operator-pause", Namespace: "ai-platform-system"}}
+
+	cached := false
+	for obj, byObject := range CacheByObject() {
+		if _, ok := obj.(*corev1.ConfigMap); ok {
+			cached = byObject.Label.Matches(labels.Set(labeled.Labels)) && !byObject.Label.Matches(labels.Set(unlabeled.Labels))
+		}
+	}
+	if !cached {
+		t.Error("the ConfigMap cache should hold the labeled ConfigMap and not the unlabeled one")
+	}
+
+	requests := r.requestsForConfigMap(context.Background(), labeled)
+	if len(requests) != 1 || requests[0].Name != "user" {
+		t.Errorf("requests for the referenced ConfigMap = %v, want only user", requests)
+	}
+	if requests := r.requestsForConfigMap(context.Background(), pause); len(requests) != 2 {
+		t.Errorf("requests for the pause ConfigMap = %v, want both deployments", requests)
+	}
+}
+
+// indexerFunc adapts a function to client.FieldIndexer
+type indexerFunc func(obj client.Object, field string, extract client.IndexerFunc)
+
+func (f indexerFunc) IndexField(_ context.Context, obj client.Object, field string, extract client.IndexerFunc) error {
+	f(obj, field, extract)
+	return nil
+}
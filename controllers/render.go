@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+const (
+	// renderManifestsAnnotation asks for the effective manifest set to be
+	// written to a ConfigMap for review on every reconcile
+	renderManifestsAnnotation = "platform.ai-platform.io/render-manifests"
+
+	// renderedSpecHashAnnotation records the spec the rendered manifests are for
+	renderedSpecHashAnnotation = "platform.ai-platform.io/spec-hash"
+
+	renderedManifestsKey = "manifests.yaml"
+
+	// maxRenderedManifestSize keeps the ConfigMap well below the 1MiB object
+	// size limit; larger manifest sets are stored gzipped
+	maxRenderedManifestSize = 900 * 1024
+)
+
+// renderedManifestsName is the ConfigMap holding a KServeDeployment's rendered manifests
+func renderedManifestsName(kd *platformv1alpha1.KServeDeployment) string {
+	return kd.Name + "-effective-manifests"
+}
+
+// renderManifests stores the rendered objects in a ConfigMap next to the
+// KServeDeployment
+func (r *KServeDeploymentReconciler) renderManifests(ctx context.Context, kd *platformv1alpha1.KServeDeployment) error {
+	rendered, err := r.renderObjects(ctx, kd)
+	if err != nil {
+		return err
+	}
+	manifests, err := encodeManifests(rendered)
+	if err != nil {
+		return err
+	}
+
+	if err := r.writeManifestsConfigMap(ctx, kd, renderedManifestsName(kd), manifests, len(rendered)); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("Rendered effective manifests", "configmap", renderedManifestsName(kd), "resources", len(rendered))
+	return nil
+}
+
+// encodeManifests writes objects as a multi-document YAML manifest
+func encodeManifests(objects []unstructured.Unstructured) ([]byte, error) {
+	var manifests bytes.Buffer
+	for i := range objects {
+		data, err := yaml.Marshal(objects[i].Object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s %s: %w", objects[i].GetKind(), objects[i].GetName(), err)
+		}
+		manifests.WriteString("---\n")
+		manifests.Write(data)
+	}
+	return manifests.Bytes(), nil
+}
+
+// renderObjects runs every component and extra manifest through the apply
+// pipeline (namespace overrides, mutators, content hash) but stops before
+// anything is sent to the cluster, returning the objects that would be
+// applied. A deployment pinned to a manifest snapshot returns the snapshot.
+// Within a reconcile the manifests are rendered once and later calls get
+// copies of the first result.
+func (r *KServeDeploymentReconciler) renderObjects(ctx context.Context, kd *platformv1alpha1.KServeDeployment) ([]unstructured.Unstructured, error) {
+	outer := reconcileStateFrom(ctx)
+	if outer == nil || outer.render {
+		return r.render(ctx, kd)
+	}
+	if outer.renderCache == nil {
+		objects, err := r.render(ctx, kd)
+		outer.renderCache = &renderResult{objects: objects, err: err}
+	}
+	if outer.renderCache.err != nil {
+		return nil, outer.renderCache.err
+	}
+
+	objects := make([]unstructured.Unstructured, len(outer.renderCache.objects))
+	for i := range outer.renderCache.objects {
+		outer.renderCache.objects[i].DeepCopyInto(&objects[i])
+	}
+	return objects, nil
+}
+
+// render does the work of renderObjects
+func (r *KServeDeploymentReconciler) render(ctx context.Context, kd *platformv1alpha1.KServeDeployment) ([]unstructured.Unstructured, error) {
+	if manifestSnapshotPinned(kd) {
+		objects, err := r.snapshotObjects(ctx, kd)
+		if err != nil {
+			return nil, err
+		}
+		if err := renderFeatureFlags(kd, objects); err != nil {
+			return nil, err
+		}
+		return objects, nil
+	}
+
+	renderCtx := withReconcileState(ctx, time.Now())
+	state := reconcileStateFrom(renderCtx)
+	state.render = true
+
+	for _, component := range kd.Spec.Components {
+		if err := r.deployComponent(renderCtx, kd, component); err != nil {
+			return nil, fmt.Errorf("component %s: %w", component, err)
+		}
+	}
+	for _, ref := range kd.Spec.ExtraManifests {
+		manifestBytes, err := r.readManifestRef(renderCtx, kd, ref)
+		if err != nil {
+			return nil, fmt.Errorf("extra manifest %s: %w", ref.Name, err)
+		}
+		if _, err := r.applyManifests(renderCtx, manifestBytes, applyOptionsFor(kd)); err != nil {
+			return nil, fmt.Errorf("extra manifest %s: %w", ref.Name, err)
+		}
+	}
+
+	if err := renderFeatureFlags(kd, state.rendered); err != nil {
+		return nil, err
+	}
+	return state.rendered, nil
+}
+
+// writeManifestsConfigMap creates or updates the ConfigMap name holding
+// manifests, owned by kd so it is garbage collected with it
+func (r *KServeDeploymentReconciler) writeManifestsConfigMap(ctx context.Context, kd *platformv1alpha1.KServeDeployment, name string, manifests []byte, count int) error {
+	cm := &corev1.ConfigMap{}
+	cm.Name = name
+	cm.Namespace = kd.Namespace
+
+	_, err := controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		cm.Labels = map[string]string{
+			ownerNameLabel:      kd.Name,
+			ownerNamespaceLabel: kd.Namespace,
+		}
+		if cm.Annotations == nil {
+			cm.Annotations = map[string]string{}
+		}
+		cm.Annotations[renderedSpecHashAnnotation] = specHash(kd)
+		cm.Data = map[string]string{"count": strconv.Itoa(count)}
+		cm.BinaryData = nil
+
+		if len(manifests) <= maxRenderedManifestSize {
+			cm.Data[renderedManifestsKey] = string(manifests)
+		} else {
+			var compressed bytes.Buffer
+			zw := gzip.NewWriter(&compressed)
+			if _, err := zw.Write(manifests); err != nil {
+				return err
+			}
+			if err := zw.Close(); err != nil {
+				return err
+			}
+			cm.BinaryData = map[string][]byte{renderedManifestsKey + ".gz": compressed.Bytes()}
+		}
+
+		return controllerutil.SetControllerReference(kd, cm, r.Scheme)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write ConfigMap %s: %w", name, err)
+	}
+	return nil
+}
+
+// readManifestsConfigMap reads the manifests written by writeManifestsConfigMap
+func (r *KServeDeploymentReconciler) readManifestsConfigMap(ctx context.Context, kd *platformv1alpha1.KServeDeployment, name string) ([]byte, error) {
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: kd.Namespace, Name: name}, cm); err != nil {
+		return nil, err
+	}
+	if data, ok := cm.Data[renderedManifestsKey]; ok {
+		return []byte(data), nil
+	}
+	compressed, ok := cm.BinaryData[renderedManifestsKey+".gz"]
+	if !ok {
+		return nil, fmt.Errorf("ConfigMap %s has no %s", name, renderedManifestsKey)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress ConfigMap %s: %w", name, err)
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
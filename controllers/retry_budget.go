@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+const (
+	// retryBudgetRefill is how long an empty budget takes to refill completely
+	retryBudgetRefill = time.Minute
+
+	// retryBudgetBackoff is how long a reconcile that ran out of budget waits
+	// before trying its failed resources again
+	retryBudgetBackoff = time.Minute
+)
+
+// RetryBudget is a token bucket that every apply retry draws from. It holds
+// Size tokens and refills over retryBudgetRefill, so during a broad outage
+// the operator as a whole backs off instead of each object retrying on its
+// own. One budget is shared by all KServeDeployment objects.
+type RetryBudget struct {
+	Size int
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRetryBudget returns a full budget of size retries. A size of zero or
+// less leaves retries unlimited.
+func NewRetryBudget(size int) *RetryBudget {
+	return &RetryBudget{
+		Size:   size,
+		tokens: float64(size),
+		last:   time.Now(),
+	}
+}
+
+// Take spends one retry. It reports false when the budget is exhausted.
+func (b *RetryBudget) Take() bool {
+	if b == nil || b.Size <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += float64(b.Size) * float64(now.Sub(b.last)) / float64(retryBudgetRefill)
+	if b.tokens > float64(b.Size) {
+		b.tokens = float64(b.Size)
+	}
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// recordRetryDenied notes a resource that wasn't retried because the budget
+// ran out
+func recordRetryDenied(ctx context.Context) {
+	if state := reconcileStateFrom(ctx); state != nil {
+		state.retriesDenied++
+	}
+}
+
+// retryBudgetExhausted reports whether this reconcile left resources
+// unretried for lack of budget
+func retryBudgetExhausted(ctx context.Context) bool {
+	state := reconcileStateFrom(ctx)
+	return state != nil && state.retriesDenied > 0
+}
+
+// setRetryBudgetCondition reports the resources this reconcile couldn't
+// retry, clearing the condition when the budget held out
+func setRetryBudgetCondition(ctx context.Context, kd *platformv1alpha1.KServeDeployment) {
+	if !retryBudgetExhausted(ctx) {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "RetryBudgetExhausted")
+		return
+	}
+
+	meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+		Type:               "RetryBudgetExhausted",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kd.Generation,
+		Reason:             platformv1alpha1.ReasonBudgetExhausted,
+		Message: fmt.Sprintf("The operator's retry budget is exhausted: %d resources were not retried, trying again in %s",
+			reconcileStateFrom(ctx).retriesDenied, retryBudgetBackoff),
+	})
+}
@@ -0,0 +1,545 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+const (
+	// runtimeVerificationPollInterval is how often runtimes being verified
+	// are checked on
+	runtimeVerificationPollInterval = 15 * time.Second
+
+	// runtimeProbeTimeout is how long a probe Pod or InferenceService has to
+	// succeed before the runtime is reported Failed
+	runtimeProbeTimeout = 15 * time.Minute
+
+	// maxProbeNameLength keeps probe names within the limits of InferenceService
+	// names, which KServe turns into Service and host names
+	maxProbeNameLength = 45
+
+	// runtimeProbeLabel marks the Pods and InferenceServices created to probe
+	// a runtime, so those left behind can be found and deleted
+	runtimeProbeLabel = "platform.ai-platform.io/runtime-probe"
+
+	// probeUserID is the unprivileged user probe containers run as
+	probeUserID = 65534
+)
+
+// imageUnresolvableReasons are the container waiting reasons of an image the
+// cluster can't pull
+var imageUnresolvableReasons = map[string]bool{
+	"ErrImagePull":      true,
+	"ImagePullBackOff":  true,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
+
+// imagePulledReasons are waiting reasons only reached once the image has been
+// pulled. Probe containers run a command the image may not have, so failing
+// to start still counts as resolvable.
+var imagePulledReasons = map[string]bool{
+	"CreateContainerError": true,
+	"RunContainerError":    true,
+	"CrashLoopBackOff":     true,
+}
+
+// verifyServingRuntimes checks that each ServingRuntime and
+// ClusterServingRuntime applied in this reconcile is usable: a probe Pod must
+// be able to pull its images, and, when a probe model is configured, a probe
+// InferenceService using it must become Ready. Results are recorded per
+// runtime in status; verified runtimes are not checked again until their
+// images change. Probes no longer needed are deleted. It returns true while
+// any runtime is still being verified.
+func (r *KServeDeploymentReconciler) verifyServingRuntimes(ctx context.Context, kd *platformv1alpha1.KServeDeployment) bool {
+	state := reconcileStateFrom(ctx)
+	if kd.Spec.RuntimeVerification == nil || state == nil {
+		r.deleteRuntimeProbes(ctx, kd, probeNamespaces(kd, nil), nil)
+		kd.Status.ServingRuntimes = nil
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "ServingRuntimesVerified")
+		return false
+	}
+
+	seen := map[platformv1alpha1.ResourceRef]bool{}
+	statuses := []platformv1alpha1.ServingRuntimeStatus{}
+	pending := false
+	failures := []string{}
+	for _, ref := range state.applied {
+		if seen[ref] || !isServingRuntimeRef(ref) {
+			continue
+		}
+		seen[ref] = true
+
+		status, err := r.verifyServingRuntime(ctx, kd, ref)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "Failed to verify serving runtime", "runtime", ref.Name)
+			status = platformv1alpha1.ServingRuntimeStatus{Name: ref.Name, Namespace: ref.Namespace, Phase: "Failed", Message: err.Error()}
+		}
+		statuses = append(statuses, status)
+
+		switch status.Phase {
+		case "Verified":
+		case "Failed":
+			failures = append(failures, fmt.Sprintf("%s: %s", runtimeKey(status), status.Message))
+		default:
+			pending = true
+		}
+	}
+	// Probes of runtimes that were removed, or whose images or probe model
+	// changed mid-check, would otherwise be left running
+	r.deleteRuntimeProbes(ctx, kd, probeNamespaces(kd, statuses), activeProbes(kd, statuses))
+	kd.Status.ServingRuntimes = statuses
+
+	condition := metav1.Condition{
+		Type:               "ServingRuntimesVerified",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kd.Generation,
+		Reason:             platformv1alpha1.ReasonVerified,
+		Message:            fmt.Sprintf("%d serving runtimes verified", len(statuses)),
+	}
+	switch {
+	case len(failures) > 0:
+		sort.Strings(failures)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = platformv1alpha1.ReasonVerificationFailed
+		condition.Message = strings.Join(failures, "; ")
+	case pending:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = platformv1alpha1.ReasonVerifying
+		condition.Message = "Serving runtimes are still being verified"
+	}
+	meta.SetStatusCondition(&kd.Status.Conditions, condition)
+	return pending
+}
+
+// verifyServingRuntime advances the verification of one runtime
+func (r *KServeDeploymentReconciler) verifyServingRuntime(ctx context.Context, kd *platformv1alpha1.KServeDeployment, ref platformv1alpha1.ResourceRef) (platformv1alpha1.ServingRuntimeStatus, error) {
+	runtime := &unstructured.Unstructured{}
+	runtime.SetAPIVersion(ref.APIVersion)
+	runtime.SetKind(ref.Kind)
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, runtime); err != nil {
+		return platformv1alpha1.ServingRuntimeStatus{}, fmt.Errorf("failed to get %s %s: %w", ref.Kind, ref.Name, err)
+	}
+
+	status := platformv1alpha1.ServingRuntimeStatus{
+		Name:       ref.Name,
+		Namespace:  ref.Namespace,
+		Images:     runtimeImages(runtime),
+		ProbeModel: kd.Spec.RuntimeVerification.ProbeModels[ref.Name],
+		Phase:      "PullingImages",
+	}
+
+	previous, found := findServingRuntimeStatus(kd.Status.ServingRuntimes, ref)
+	unchanged := found && previous.ProbeModel == status.ProbeModel && equalStrings(previous.Images, status.Images)
+	if unchanged && previous.Phase == "Verified" {
+		return previous, nil
+	}
+
+	// ServingRuntimes are only usable from their own namespace
+	namespace := ref.Namespace
+	if namespace == "" {
+		namespace = kd.Namespace
+	}
+
+	if !unchanged || previous.Phase != "ProbingInferenceService" {
+		phase, message, err := r.probeRuntimeImages(ctx, kd, ref.Name, namespace, status.Images)
+		if err != nil || phase != "Verified" {
+			status.Phase, status.Message = phase, message
+			return status, err
+		}
+	}
+
+	if status.ProbeModel == "" {
+		status.Phase = "Verified"
+		return status, nil
+	}
+
+	phase, message, err := r.probeRuntimeInferenceService(ctx, kd, runtime, namespace, status.ProbeModel)
+	status.Phase, status.Message = phase, message
+	return status, err
+}
+
+// probeRuntimeImages pulls images with a short-lived Pod. It returns Verified
+// once every image was pulled, Failed when one can't be, and PullingImages
+// until then. The Pod is deleted once the outcome is known.
+func (r *KServeDeploymentReconciler) probeRuntimeImages(ctx context.Context, kd *platformv1alpha1.KServeDeployment, runtime, namespace string, images []string) (string, string, error) {
+	if len(images) == 0 {
+		return "Verified", "", nil
+	}
+
+	key := client.ObjectKey{Namespace: namespace, Name: probeName(kd.Name, runtime, strings.Join(images, ","))}
+	pod := &corev1.Pod{}
+	if err := r.Get(ctx, key, pod); err != nil {
+		if !errors.IsNotFound(err) {
+			return "Failed", "", fmt.Errorf("failed to get probe Pod %s: %w", key, err)
+		}
+		log.FromContext(ctx).Info("Creating image probe Pod", "runtime", runtime, "pod", key.Name, "namespace", key.Namespace)
+		pod = newImageProbePod(kd, key, images)
+		if err := r.setLocalOwner(kd, pod); err != nil {
+			return "Failed", "", err
+		}
+		if err := r.Create(ctx, pod); err != nil {
+			return "Failed", "", fmt.Errorf("failed to create probe Pod %s: %w", key, err)
+		}
+		return "PullingImages", "", nil
+	}
+
+	phase, message := imageProbeOutcome(pod)
+	if phase == "PullingImages" && time.Since(pod.CreationTimestamp.Time) > runtimeProbeTimeout {
+		phase, message = "Failed", fmt.Sprintf("images were not pulled within %s", runtimeProbeTimeout)
+	}
+	if phase != "PullingImages" {
+		propagation := metav1.DeletePropagationBackground
+		if err := r.Delete(ctx, pod, &client.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !errors.IsNotFound(err) {
+			log.FromContext(ctx).Error(err, "Failed to delete probe Pod", "pod", key.Name)
+		}
+	}
+	return phase, message, nil
+}
+
+// imageProbeOutcome reads the result of an image probe Pod from its
+// container statuses
+func imageProbeOutcome(pod *corev1.Pod) (string, string) {
+	if len(pod.Status.ContainerStatuses) < len(pod.Spec.Containers) {
+		return "PullingImages", ""
+	}
+
+	for _, cs := range pod.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && imageUnresolvableReasons[cs.State.Waiting.Reason] {
+			return "Failed", fmt.Sprintf("image %s can't be pulled (%s): %s", cs.Image, cs.State.Waiting.Reason, cs.State.Waiting.Message)
+		}
+	}
+	for _, cs := range pod.Status.ContainerStatuses {
+		pulled := cs.State.Running != nil || cs.State.Terminated != nil ||
+			(cs.State.Waiting != nil && imagePulledReasons[cs.State.Waiting.Reason])
+		if !pulled {
+			return "PullingImages", ""
+		}
+	}
+	return "Verified", ""
+}
+
+// newImageProbePod returns a Pod with one container per image. The containers
+// only need to be created, so they run a no-op with minimal resources. They
+// meet the restricted Pod Security Standard, so namespaces enforcing it admit
+// the Pod.
+func newImageProbePod(kd *platformv1alpha1.KServeDeployment, key client.ObjectKey, images []string) *corev1.Pod {
+	deadline := int64(runtimeProbeTimeout.Seconds())
+	automount := false
+	nonRoot := true
+	user := int64(probeUserID)
+	escalation := false
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("10m"),
+		corev1.ResourceMemory: resource.MustParse("16Mi"),
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Labels:    runtimeProbeLabels(kd),
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                corev1.RestartPolicyNever,
+			ActiveDeadlineSeconds:        &deadline,
+			AutomountServiceAccountToken: &automount,
+			SecurityContext: &corev1.PodSecurityContext{
+				RunAsNonRoot:   &nonRoot,
+				RunAsUser:      &user,
+				SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			},
+		},
+	}
+	for i, image := range images {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{
+			Name:      fmt.Sprintf("image-%d", i),
+			Image:     image,
+			Command:   []string{"true"},
+			Resources: corev1.ResourceRequirements{Requests: resources, Limits: resources},
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: &escalation,
+				Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+			},
+		})
+	}
+	for _, name := range kd.Spec.ImagePullSecrets {
+		pod.Spec.ImagePullSecrets = append(pod.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+	}
+	return pod
+}
+
+// probeRuntimeInferenceService serves model with runtime through a probe
+// InferenceService. It returns Verified once that is Ready, Failed when it
+// isn't within the probe timeout, and ProbingInferenceService until then.
+// The InferenceService is deleted once the outcome is known.
+func (r *KServeDeploymentReconciler) probeRuntimeInferenceService(ctx context.Context, kd *platformv1alpha1.KServeDeployment, runtime *unstructured.Unstructured, namespace, model string) (string, string, error) {
+	isvc := &unstructured.Unstructured{}
+	isvc.SetAPIVersion("serving.kserve.io/v1beta1")
+	isvc.SetKind("InferenceService")
+	key := client.ObjectKey{Namespace: namespace, Name: probeName(kd.Name, runtime.GetName(), model)}
+
+	if err := r.Get(ctx, key, isvc); err != nil {
+		if !errors.IsNotFound(err) {
+			return "Failed", "", fmt.Errorf("failed to get probe InferenceService %s: %w", key, err)
+		}
+		probe, err := newProbeInferenceService(kd, key, runtime, model)
+		if err != nil {
+			return "Failed", err.Error(), nil
+		}
+		if err := r.setLocalOwner(kd, probe); err != nil {
+			return "Failed", "", err
+		}
+		log.FromContext(ctx).Info("Creating probe InferenceService", "runtime", runtime.GetName(), "inferenceservice", key.Name, "namespace", key.Namespace)
+		if err := r.Create(ctx, probe); err != nil {
+			return "Failed", "", fmt.Errorf("failed to create probe InferenceService %s: %w", key, err)
+		}
+		return "ProbingInferenceService", "", nil
+	}
+
+	phase, message := "ProbingInferenceService", ""
+	switch ready, reason := inferenceServiceReady(isvc); {
+	case ready:
+		phase = "Verified"
+	case time.Since(isvc.GetCreationTimestamp().Time) > runtimeProbeTimeout:
+		phase, message = "Failed", fmt.Sprintf("probe InferenceService was not Ready within %s: %s", runtimeProbeTimeout, reason)
+	}
+	if phase != "ProbingInferenceService" {
+		if err := r.Delete(ctx, isvc); err != nil && !errors.IsNotFound(err) {
+			log.FromContext(ctx).Error(err, "Failed to delete probe InferenceService", "inferenceservice", key.Name)
+		}
+	}
+	return phase, message, nil
+}
+
+// newProbeInferenceService returns an InferenceService serving model with
+// runtime, using the first model format the runtime supports
+func newProbeInferenceService(kd *platformv1alpha1.KServeDeployment, key client.ObjectKey, runtime *unstructured.Unstructured, model string) (*unstructured.Unstructured, error) {
+	formats, _, _ := unstructured.NestedSlice(runtime.Object, "spec", "supportedModelFormats")
+	format := ""
+	if len(formats) > 0 {
+		if m, ok := formats[0].(map[string]interface{}); ok {
+			format, _ = m["name"].(string)
+		}
+	}
+	if format == "" {
+		return nil, fmt.Errorf("runtime %s lists no supportedModelFormats to probe with", runtime.GetName())
+	}
+
+	isvc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"predictor": map[string]interface{}{
+				"model": map[string]interface{}{
+					"modelFormat": map[string]interface{}{"name": format},
+					"runtime":     runtime.GetName(),
+					"storageUri":  model,
+				},
+			},
+		},
+	}}
+	isvc.SetAPIVersion("serving.kserve.io/v1beta1")
+	isvc.SetKind("InferenceService")
+	isvc.SetName(key.Name)
+	isvc.SetNamespace(key.Namespace)
+	isvc.SetLabels(runtimeProbeLabels(kd))
+	return isvc, nil
+}
+
+// runtimeProbeLabels are the labels of the probes created for kd
+func runtimeProbeLabels(kd *platformv1alpha1.KServeDeployment) map[string]string {
+	return map[string]string{
+		ownerNameLabel:      kd.Name,
+		ownerNamespaceLabel: kd.Namespace,
+		runtimeProbeLabel:   "true",
+	}
+}
+
+// setLocalOwner makes kd own an object it created in its own namespace, so
+// deleting kd garbage collects it. Owner references can't cross namespaces;
+// objects elsewhere are found through their labels or ManagedResources.
+func (r *KServeDeploymentReconciler) setLocalOwner(kd *platformv1alpha1.KServeDeployment, obj client.Object) error {
+	if obj.GetNamespace() != kd.Namespace {
+		return nil
+	}
+	if err := controllerutil.SetControllerReference(kd, obj, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set owner of %s: %w", obj.GetName(), err)
+	}
+	return nil
+}
+
+// activeProbes lists the probes still needed by the runtimes being verified
+func activeProbes(kd *platformv1alpha1.KServeDeployment, statuses []platformv1alpha1.ServingRuntimeStatus) map[client.ObjectKey]bool {
+	active := map[client.ObjectKey]bool{}
+	for _, status := range statuses {
+		namespace := status.Namespace
+		if namespace == "" {
+			namespace = kd.Namespace
+		}
+		switch status.Phase {
+		case "PullingImages":
+			active[client.ObjectKey{Namespace: namespace, Name: probeName(kd.Name, status.Name, strings.Join(status.Images, ","))}] = true
+		case "ProbingInferenceService":
+			active[client.ObjectKey{Namespace: namespace, Name: probeName(kd.Name, status.Name, status.ProbeModel)}] = true
+		}
+	}
+	return active
+}
+
+// probeNamespaces lists the namespaces probes for kd may have been created in:
+// the deployment's own and those of the runtimes verified now or before
+func probeNamespaces(kd *platformv1alpha1.KServeDeployment, statuses []platformv1alpha1.ServingRuntimeStatus) []string {
+	namespaces := []string{kd.Namespace}
+	for _, list := range [][]platformv1alpha1.ServingRuntimeStatus{kd.Status.ServingRuntimes, statuses} {
+		for _, status := range list {
+			if status.Namespace != "" {
+				namespaces = append(namespaces, status.Namespace)
+			}
+		}
+	}
+	sort.Strings(namespaces)
+	return uniqueStrings(namespaces)
+}
+
+// deleteRuntimeProbes deletes the probe Pods and InferenceServices created for
+// kd in namespaces, except the active ones. Failures are logged; the next
+// reconcile tries again.
+func (r *KServeDeploymentReconciler) deleteRuntimeProbes(ctx context.Context, kd *platformv1alpha1.KServeDeployment, namespaces []string, active map[client.ObjectKey]bool) {
+	logger := log.FromContext(ctx)
+	labels := client.MatchingLabels(runtimeProbeLabels(kd))
+	propagation := metav1.DeletePropagationBackground
+
+	for _, namespace := range namespaces {
+		pods := &corev1.PodList{}
+		if err := r.List(ctx, pods, client.InNamespace(namespace), labels); err != nil {
+			logger.Error(err, "Failed to list probe Pods", "namespace", namespace)
+		}
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if active[client.ObjectKeyFromObject(pod)] {
+				continue
+			}
+			logger.Info("Deleting probe Pod", "pod", pod.Name, "namespace", namespace)
+			if err := r.Delete(ctx, pod, &client.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !errors.IsNotFound(err) {
+				logger.Error(err, "Failed to delete probe Pod", "pod", pod.Name, "namespace", namespace)
+			}
+		}
+
+		isvcs := &unstructured.UnstructuredList{}
+		isvcs.SetAPIVersion("serving.kserve.io/v1beta1")
+		isvcs.SetKind("InferenceServiceList")
+		if err := r.List(ctx, isvcs, client.InNamespace(namespace), labels); err != nil {
+			// Without KServe installed there are no probe InferenceServices
+			if !meta.IsNoMatchError(err) {
+				logger.Error(err, "Failed to list probe InferenceServices", "namespace", namespace)
+			}
+		}
+		for i := range isvcs.Items {
+			isvc := &isvcs.Items[i]
+			if active[client.ObjectKeyFromObject(isvc)] {
+				continue
+			}
+			logger.Info("Deleting probe InferenceService", "inferenceservice", isvc.GetName(), "namespace", namespace)
+			if err := r.Delete(ctx, isvc); err != nil && !errors.IsNotFound(err) {
+				logger.Error(err, "Failed to delete probe InferenceService", "inferenceservice", isvc.GetName(), "namespace", namespace)
+			}
+		}
+	}
+}
+
+// inferenceServiceReady reports whether an InferenceService's Ready
+// condition is True, and the condition's reason otherwise
+func inferenceServiceReady(isvc *unstructured.Unstructured) (bool, string) {
+	conditions, _, _ := unstructured.NestedSlice(isvc.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok || m["type"] != "Ready" {
+			continue
+		}
+		if m["status"] == "True" {
+			return true, ""
+		}
+		reason, _ := m["reason"].(string)
+		message, _ := m["message"].(string)
+		return false, strings.TrimSpace(reason + " " + message)
+	}
+	return false, "no Ready condition"
+}
+
+// runtimeImages lists the container images of a runtime in a stable order
+func runtimeImages(runtime *unstructured.Unstructured) []string {
+	containers, _, _ := unstructured.NestedSlice(runtime.Object, "spec", "containers")
+	images := []string{}
+	for _, c := range containers {
+		if m, ok := c.(map[string]interface{}); ok {
+			if image, ok := m["image"].(string); ok && image != "" {
+				images = append(images, image)
+			}
+		}
+	}
+	sort.Strings(images)
+	return uniqueStrings(images)
+}
+
+// probeName builds a probe object name from the deployment and runtime names,
+// shortened with a hash of everything when too long. The hash of detail
+// distinguishes probes of different images or models.
+func probeName(deployment, runtime, detail string) string {
+	sum := sha256.Sum256([]byte(deployment + "/" + runtime + "/" + detail))
+	suffix := hex.EncodeToString(sum[:])[:8]
+
+	name := fmt.Sprintf("%s-%s", deployment, runtime)
+	if len(name) > maxProbeNameLength-len(suffix)-1 {
+		name = strings.TrimRight(name[:maxProbeNameLength-len(suffix)-1], "-.")
+	}
+	return name + "-" + suffix
+}
+
+func isServingRuntimeRef(ref platformv1alpha1.ResourceRef) bool {
+	return strings.HasPrefix(ref.APIVersion, "serving.kserve.io/") &&
+		(ref.Kind == "ServingRuntime" || ref.Kind == "ClusterServingRuntime")
+}
+
+func runtimeKey(status platformv1alpha1.ServingRuntimeStatus) string {
+	if status.Namespace == "" {
+		return status.Name
+	}
+	return status.Namespace + "/" + status.Name
+}
+
+func findServingRuntimeStatus(statuses []platformv1alpha1.ServingRuntimeStatus, ref platformv1alpha1.ResourceRef) (platformv1alpha1.ServingRuntimeStatus, bool) {
+	for _, s := range statuses {
+		if s.Name == ref.Name && s.Namespace == ref.Namespace {
+			return s, true
+		}
+	}
+	return platformv1alpha1.ServingRuntimeStatus{}, false
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+func TestDeleteRuntimeProbesKeepsOnlyActiveProbes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := platformv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	kd := &platformv1alpha1.KServeDeployment{ObjectMeta: metav1.ObjectMeta{Name: "kserve", Namespace: "platform"}}
+	kd.Status.ServingRuntimes = []platformv1alpha1.ServingRuntimeStatus{
+		{Name: "old-runtime", Namespace: "models", Phase: "PullingImages", Images: []string{"old:1"}},
+	}
+	statuses := []platformv1alpha1.ServingRuntimeStatus{
+		{Name: "sklearn", Phase: "PullingImages", Images: []string{"sklearn:1"}},
+	}
+
+	active := newImageProbePod(kd, client.ObjectKey{Namespace: "platform", Name: probeName(kd.Name, "sklearn", "sklearn:1")}, []string{"sklearn:1"})
+	outdated := newImageProbePod(kd, client.ObjectKey{Namespace: "platform", Name: probeName(kd.Name, "sklearn", "sklearn:0")}, []string{"sklearn:0"})
+	removed := newImageProbePod(kd, client.ObjectKey{Namespace: "models", Name: probeName(kd.Name, "old-runtime", "old:1")}, []string{"old:1"})
+	unrelated := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "user-pod", Namespace: "models"}}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(active, outdated, removed, unrelated).Build()
+	r := &KServeDeploymentReconciler{Client: c, Scheme: scheme}
+
+	ctx := context.Background()
+	r.deleteRuntimeProbes(ctx, kd, probeNamespaces(kd, statuses), activeProbes(kd, statuses))
+
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods); err != nil {
+		t.Fatal(err)
+	}
+	left := map[string]bool{}
+	for _, pod := range pods.Items {
+		left[pod.Namespace+"/"+pod.Name] = true
+	}
+	want := map[string]bool{
+		"platform/" + active.Name: true,
+		"models/user-pod":         true,
+	}
+	if len(left) != len(want) {
+		t.Fatalf("pods left = %v, want %v", left, want)
+	}
+	for key := range want {
+		if !left[key] {
+			t.Fatalf("pods left = %v, want %v", left, want)
+		}
+	}
+}
+
+func TestImageProbePodIsRestricted(t *testing.T) {
+	kd := &platformv1alpha1.KServeDeployment{ObjectMeta: metav1.ObjectMeta{Name: "kserve", Namespace: "platform"}}
+	pod := newImageProbePod(kd, client.ObjectKey{Namespace: "platform", Name: "probe"}, []string{"a:1", "b:1"})
+
+	sc := pod.Spec.SecurityContext
+	if sc == nil || sc.RunAsNonRoot == nil || !*sc.RunAsNonRoot || sc.SeccompProfile == nil || sc.SeccompProfile.Type != corev1.SeccompProfileTypeRuntimeDefault {
+		t.Fatalf("pod securityContext = %+v, want non-root with the RuntimeDefault seccomp profile", sc)
+	}
+	for _, container := range pod.Spec.Containers {
+		csc := container.SecurityContext
+		if csc == nil || csc.AllowPrivilegeEscalation == nil || *csc.AllowPrivilegeEscalation ||
+			csc.Capabilities == nil || len(csc.Capabilities.Drop) != 1 || csc.Capabilities.Drop[0] != "ALL" {
+			t.Fatalf("container %s securityContext = %+v, want no privilege escalation and all capabilities dropped", container.Name, csc)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// cronSchedule is a parsed five-field cron expression, evaluated in UTC. Each
+// field is a bit set of the values it matches.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domStar and dowStar record an unrestricted day field; when both day
+	// fields are restricted either may match, as in cron
+	domStar, dowStar bool
+}
+
+var cronFields = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCronSchedule parses a cron expression such as "0 3 * * 1-5" or one of
+// the @daily style macros
+func parseCronSchedule(expr string) (*cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := cronMacros[expr]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("expected 5 fields (minute hour day-of-month month day-of-week), got %d", len(fields))
+	}
+
+	bits := make([]uint64, len(fields))
+	for i, field := range fields {
+		b, err := parseCronField(field, cronFields[i].min, cronFields[i].max)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cronFields[i].name, err)
+		}
+		bits[i] = b
+	}
+
+	// 7 is Sunday as well as 0
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	schedule := &cronSchedule{
+		minute:  bits[0],
+		hour:    bits[1],
+		dom:     bits[2],
+		month:   bits[3],
+		dow:     bits[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+	if schedule.next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("%q never fires", expr)
+	}
+	return schedule, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges and steps
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		valueRange, stepText, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		lo, hi := min, max
+		if valueRange != "*" {
+			loText, hiText, isRange := strings.Cut(valueRange, "-")
+			var err error
+			if lo, err = strconv.Atoi(loText); err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			switch {
+			case isRange:
+				if hi, err = strconv.Atoi(hiText); err != nil {
+					return 0, fmt.Errorf("invalid range %q", part)
+				}
+			case !hasStep:
+				hi = lo
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// next returns the first time after the given one the schedule fires, or the
+// zero time when it doesn't fire within five years
+func (s *cronSchedule) next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// scheduledReconcileDue reports whether this reconcile is the scheduled one.
+// A requeue can fire a moment early, so a second of slack is allowed.
+func scheduledReconcileDue(kd *platformv1alpha1.KServeDeployment, now time.Time) bool {
+	next := kd.Status.NextScheduledReconcile
+	return kd.Spec.ReconcileSchedule != "" && next != nil && !now.Add(time.Second).Before(next.Time)
+}
+
+// scheduleNextReconcile records the next scheduled reconcile in status and
+// returns how long until it, or zero without a (valid) schedule. The next
+// run is counted from when the reconcile started, and from after the run it
+// served if it was the scheduled one, so a run that falls while a reconcile
+// is in progress isn't skipped, and one that fired a moment early isn't
+// repeated. Status writes later in the same reconcile keep the same next run.
+func scheduleNextReconcile(kd *platformv1alpha1.KServeDeployment, start, now time.Time) time.Duration {
+	from := start
+	if previous := kd.Status.NextScheduledReconcile; scheduledReconcileDue(kd, start) && previous.Time.After(from) {
+		from = previous.Time
+	}
+
+	kd.Status.NextScheduledReconcile = nil
+	if kd.Spec.ReconcileSchedule == "" {
+		return 0
+	}
+
+	schedule, err := parseCronSchedule(kd.Spec.ReconcileSchedule)
+	if err != nil {
+		return 0
+	}
+	next := schedule.next(from)
+	kd.Status.NextScheduledReconcile = &metav1.Time{Time: next}
+
+	// A run that fell during this reconcile is due right away
+	if until := next.Sub(now); until > time.Second {
+		return until
+	}
+	return time.Second
+}
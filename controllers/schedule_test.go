@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+func TestParseCronScheduleRejects(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"1-a * * * *",
+		"@fortnightly",
+		"0 0 31 2 *",
+	} {
+		if _, err := parseCronSchedule(expr); err == nil {
+			t.Errorf("parseCronSchedule(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	// A Wednesday
+	after := time.Date(2025, time.January, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"* * * * *", []string{"2025-01-15T10:08", "2025-01-15T10:09"}},
+		{"*/15 * * * *", []string{"2025-01-15T10:15", "2025-01-15T10:30", "2025-01-15T10:45", "2025-01-15T11:00"}},
+		{"5/20 * * * *", []string{"2025-01-15T10:25", "2025-01-15T10:45", "2025-01-15T11:05"}},
+		{"0,30 9-11 * * *", []string{"2025-01-15T10:30", "2025-01-15T11:00", "2025-01-15T11:30", "2025-01-16T09:00"}},
+		{"0 8-18/4 * * *", []string{"2025-01-15T12:00", "2025-01-15T16:00", "2025-01-16T08:00"}},
+		{"0 3 * * 1-5", []string{"2025-01-16T03:00", "2025-01-17T03:00", "2025-01-20T03:00"}},
+		{"0 0 * * 7", []string{"2025-01-19T00:00", "2025-01-26T00:00"}},
+		{"0 0 * * 5-7", []string{"2025-01-17T00:00", "2025-01-18T00:00", "2025-01-19T00:00", "2025-01-24T00:00"}},
+		{"0 0 31 * *", []string{"2025-01-31T00:00", "2025-03-31T00:00", "2025-05-31T00:00"}},
+		{"0 0 29 2 *", []string{"2028-02-29T00:00"}},
+		// Both day fields restricted: either may match
+		{"0 0 1 * 1", []string{"2025-01-20T00:00", "2025-01-27T00:00", "2025-02-01T00:00", "2025-02-03T00:00"}},
+		// A starred day field with a step still restricts by the other one alone
+		{"0 0 */2 * 1", []string{"2025-01-27T00:00", "2025-02-03T00:00"}},
+		{"0 0 1 */3 *", []string{"2025-04-01T00:00", "2025-07-01T00:00", "2025-10-01T00:00", "2026-01-01T00:00"}},
+		{"@daily", []string{"2025-01-16T00:00", "2025-01-17T00:00"}},
+		{"@weekly", []string{"2025-01-19T00:00", "2025-01-26T00:00"}},
+		{"@monthly", []string{"2025-02-01T00:00", "2025-03-01T00:00"}},
+		{"@yearly", []string{"2026-01-01T00:00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := parseCronSchedule(tt.expr)
+			if err != nil {
+				t.Fatalf("parseCronSchedule(%q) failed: %v", tt.expr, err)
+			}
+			next := after
+			for _, want := range tt.want {
+				next = schedule.next(next)
+				if got := next.Format("2006-01-02T15:04"); got != want {
+					t.Fatalf("next = %s, want %s", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestScheduleNextReconcile(t *testing.T) {
+	at := func(clock string) time.Time {
+		t, _ := time.Parse(time.RFC3339, "2025-01-15T"+clock+"Z")
+		return t
+	}
+	scheduled := func(next time.Time) *platformv1alpha1.KServeDeployment {
+		kd := &platformv1alpha1.KServeDeployment{}
+		kd.Spec.ReconcileSchedule = "0 3 * * *"
+		if !next.IsZero() {
+			kd.Status.NextScheduledReconcile = &metav1.Time{Time: next}
+		}
+		return kd
+	}
+
+	tests := []struct {
+		name      string
+		kd        *platformv1alpha1.KServeDeployment
+		start     time.Time
+		now       time.Time
+		wantNext  time.Time
+		wantAfter time.Duration
+	}{
+		{
+			name:      "first schedule",
+			kd:        scheduled(time.Time{}),
+			start:     at("01:00:00"),
+			now:       at("01:00:05"),
+			wantNext:  at("03:00:00"),
+			wantAfter: 2*time.Hour - 5*time.Second,
+		},
+		{
+			name:      "run falls during the reconcile",
+			kd:        scheduled(at("03:00:00")),
+			start:     at("02:59:30"),
+			now:       at("03:00:20"),
+			wantNext:  at("03:00:00"),
+			wantAfter: time.Second,
+		},
+		{
+			name:      "scheduled run",
+			kd:        scheduled(at("03:00:00")),
+			start:     at("03:00:00"),
+			now:       at("03:00:40"),
+			wantNext:  at("03:00:00").AddDate(0, 0, 1),
+			wantAfter: 24*time.Hour - 40*time.Second,
+		},
+		{
+			name:      "scheduled run requeued early",
+			kd:        scheduled(at("03:00:00")),
+			start:     at("02:59:59.5"),
+			now:       at("03:00:10"),
+			wantNext:  at("03:00:00").AddDate(0, 0, 1),
+			wantAfter: 24*time.Hour - 10*time.Second,
+		},
+		{
+			name:      "second status write of the scheduled run",
+			kd:        scheduled(at("03:00:00").AddDate(0, 0, 1)),
+			start:     at("03:00:00"),
+			now:       at("03:01:00"),
+			wantNext:  at("03:00:00").AddDate(0, 0, 1),
+			wantAfter: 24*time.Hour - time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := scheduleNextReconcile(tt.kd, tt.start, tt.now)
+			next := tt.kd.Status.NextScheduledReconcile
+			if next == nil || !next.Time.Equal(tt.wantNext) {
+				t.Errorf("next scheduled reconcile = %v, want %v", next, tt.wantNext)
+			}
+			if after != tt.wantAfter {
+				t.Errorf("requeue after %v, want %v", after, tt.wantAfter)
+			}
+		})
+	}
+
+	kd := &platformv1alpha1.KServeDeployment{}
+	kd.Status.NextScheduledReconcile = &metav1.Time{Time: at("03:00:00")}
+	if after := scheduleNextReconcile(kd, at("01:00:00"), at("01:00:00")); after != 0 || kd.Status.NextScheduledReconcile != nil {
+		t.Errorf("without a schedule: requeue after %v, next %v; want neither", after, kd.Status.NextScheduledReconcile)
+	}
+}
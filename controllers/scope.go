@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// clusterScopedComponents install cluster-scoped resources (CRDs, webhook
+// configurations, ClusterRoles) and so can't run in single-namespace mode
+var clusterScopedComponents = map[string]bool{
+	"kserve":       true,
+	"cert-manager": true,
+	"knative":      true,
+	"istio":        true,
+}
+
+// checkScope rejects components that need cluster-wide access when the
+// operator is restricted to WatchNamespace
+func (r *KServeDeploymentReconciler) checkScope(kd *platformv1alpha1.KServeDeployment) error {
+	if r.WatchNamespace == "" {
+		return nil
+	}
+
+	for _, component := range kd.Spec.Components {
+		if clusterScopedComponents[component] {
+			return fmt.Errorf("component %s installs cluster-scoped resources (CRDs) and cannot be deployed while the operator is restricted to namespace %s", component, r.WatchNamespace)
+		}
+	}
+	return nil
+}
+
+// checkObjectScope ensures obj lands in WatchNamespace when the operator is
+// running in single-namespace mode. Cluster-scoped objects are rejected
+// whatever the operator's RBAC allows.
+func (r *KServeDeploymentReconciler) checkObjectScope(obj *unstructured.Unstructured) error {
+	if r.WatchNamespace == "" {
+		return nil
+	}
+
+	namespaced, err := r.IsObjectNamespaced(obj)
+	if err != nil {
+		return fmt.Errorf("failed to determine scope of %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	if !namespaced {
+		return fmt.Errorf("%s %s is cluster-scoped and cannot be applied while the operator is restricted to namespace %s", obj.GetKind(), obj.GetName(), r.WatchNamespace)
+	}
+	if obj.GetNamespace() != r.WatchNamespace {
+		return fmt.Errorf("%s %s targets namespace %s but the operator is restricted to namespace %s", obj.GetKind(), obj.GetName(), obj.GetNamespace(), r.WatchNamespace)
+	}
+	return nil
+}
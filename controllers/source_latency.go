@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+const (
+	// latencySmoothing weights the newest fetch in a source's moving average
+	latencySmoothing = 0.2
+
+	// latencyWarmupSamples fetches are averaged before a source can be flagged
+	latencyWarmupSamples = 3
+)
+
+// LatencyTracker keeps an exponential moving average of fetch latency per
+// manifest source and flags fetches that take more than Factor times the
+// average, so a degrading mirror shows up before its circuit breaker opens.
+// One tracker is shared by all KServeDeployment objects.
+type LatencyTracker struct {
+	Factor float64
+
+	mu      sync.Mutex
+	sources map[string]*sourceLatency
+}
+
+type sourceLatency struct {
+	average time.Duration
+	samples int
+}
+
+// NewLatencyTracker returns a tracker that flags fetches slower than factor
+// times the source's average. A factor of zero or less flags nothing.
+func NewLatencyTracker(factor float64) *LatencyTracker {
+	return &LatencyTracker{
+		Factor:  factor,
+		sources: map[string]*sourceLatency{},
+	}
+}
+
+// Observe folds a successful fetch into the average for source. It reports
+// whether the fetch was slow, along with the average it was compared to.
+func (t *LatencyTracker) Observe(source string, elapsed time.Duration) (bool, time.Duration) {
+	if t == nil {
+		return false, 0
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state, ok := t.sources[source]
+	if !ok {
+		t.sources[source] = &sourceLatency{average: elapsed, samples: 1}
+		return false, 0
+	}
+
+	average := state.average
+	slow := t.Factor > 0 && state.samples >= latencyWarmupSamples &&
+		float64(elapsed) > t.Factor*float64(average)
+
+	state.average = time.Duration(latencySmoothing*float64(elapsed) + (1-latencySmoothing)*float64(average))
+	state.samples++
+	return slow, average
+}
+
+// observeFetchLatency records a successful download of source in the fetch
+// latency metric and remembers slow fetches for the SlowManifestSource
+// condition
+func (r *KServeDeploymentReconciler) observeFetchLatency(ctx context.Context, source string, elapsed time.Duration) {
+	observeManifestFetch(sourceHost(source), elapsed)
+
+	slow, average := r.SourceLatency.Observe(source, elapsed)
+	if !slow {
+		return
+	}
+	if state := reconcileStateFrom(ctx); state != nil {
+		state.slowSources = append(state.slowSources, fmt.Sprintf("%s took %s (average %s)",
+			source, elapsed.Round(time.Millisecond), average.Round(time.Millisecond)))
+	}
+}
+
+// setSlowSourceCondition reports the manifest sources that were unusually
+// slow during this reconcile, clearing the condition when none were
+func setSlowSourceCondition(ctx context.Context, kd *platformv1alpha1.KServeDeployment) {
+	state := reconcileStateFrom(ctx)
+	if state == nil || len(state.slowSources) == 0 {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "SlowManifestSource")
+		return
+	}
+
+	meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+		Type:               "SlowManifestSource",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kd.Generation,
+		Reason:             platformv1alpha1.ReasonLatencyAboveAverage,
+		Message:            strings.Join(state.slowSources, "; "),
+	})
+}
+
+// sourceHost labels fetch metrics by host so every release URL of a mirror
+// shares one series
+func sourceHost(source string) string {
+	u, err := url.Parse(source)
+	if err != nil || u.Host == "" {
+		return "unknown"
+	}
+	return u.Host
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// checkSpec runs the webhook's validation inline, so specs that got past a
+// disabled (or not yet deployed) webhook are caught too. It returns the
+// validation error, or nil for a valid spec.
+func checkSpec(kd *platformv1alpha1.KServeDeployment) error {
+	errs := kd.ValidateSpec()
+	if len(errs) == 0 {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "SpecInvalid")
+		return nil
+	}
+
+	err := errs.ToAggregate()
+	meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+		Type:               "SpecInvalid",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kd.Generation,
+		Reason:             platformv1alpha1.ReasonValidationFailed,
+		Message:            err.Error(),
+	})
+	return err
+}
+
+// handleInvalidSpec applies the InvalidSpecPolicy. By default the last good
+// install keeps running with its phase and version unchanged; TearDown
+// deletes everything the operator applied, short of the CRDs and namespaces
+// retainedOnDelete keeps, and marks the deployment Failed.
+func (r *KServeDeploymentReconciler) handleInvalidSpec(ctx context.Context, kd *platformv1alpha1.KServeDeployment, invalid error) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	message := fmt.Sprintf("Spec is invalid: %s", invalid)
+
+	if kd.Spec.InvalidSpecPolicy != "TearDown" {
+		logger.Info("Spec is invalid, keeping the last good install", "reason", invalid.Error())
+		phase := kd.Status.Phase
+		if kd.Status.InstalledVersion == "" {
+			// Nothing was installed to keep running
+			phase = "Failed"
+		}
+		return r.updateStatusWithReason(ctx, kd, phase, platformv1alpha1.ReasonValidationFailed, kd.Status.InstalledVersion, kd.Status.InstalledComponents, message)
+	}
+
+	if len(kd.Status.ManagedResources) > 0 {
+		logger.Info("Spec is invalid, tearing down the install", "count", len(kd.Status.ManagedResources), "reason", invalid.Error())
+		retained := r.deleteResources(ctx, kd, reverseResourceRefs(kd.Status.ManagedResources))
+		r.recordEvent(kd, corev1.EventTypeWarning, "InstallTornDown",
+			fmt.Sprintf("Deleted %d resources because the spec is invalid", len(kd.Status.ManagedResources)-len(retained)))
+		// Retained CRDs and namespaces stay managed, so deleting the
+		// KServeDeployment can still clean them up
+		kd.Status.ManagedResources = reverseResourceRefs(retained)
+	}
+	kd.Status.ExtraManifests = nil
+	kd.Status.ComponentStatuses = nil
+	kd.Status.UpgradeCheckpoint = nil
+	return r.updateStatusWithReason(ctx, kd, "Failed", platformv1alpha1.ReasonValidationFailed, "", nil, message)
+}
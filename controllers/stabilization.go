@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// stabilizePhase applies hysteresis to the Degraded -> Ready transition: a
+// degraded deployment must stay healthy for StabilizationPeriod before it is
+// reported Ready again. It returns the phase to report, a status message
+// while recovery is pending, and when to check again. Events are only
+// emitted on confirmed transitions.
+func (r *KServeDeploymentReconciler) stabilizePhase(kd *platformv1alpha1.KServeDeployment, phase string) (string, string, time.Duration) {
+	previous := kd.Status.Phase
+
+	if phase == "Degraded" {
+		kd.Status.HealthySince = nil
+		if previous != "Degraded" {
+			r.recordEvent(kd, corev1.EventTypeWarning, "Degraded", "KServe deployment became degraded")
+		}
+		return phase, "", 0
+	}
+
+	if phase != "Ready" || previous != "Degraded" || r.StabilizationPeriod <= 0 {
+		kd.Status.HealthySince = nil
+		if phase == "Ready" && previous == "Degraded" {
+			r.recordEvent(kd, corev1.EventTypeNormal, "Recovered", "KServe deployment recovered")
+		}
+		return phase, "", 0
+	}
+
+	if kd.Status.HealthySince == nil {
+		now := metav1.Now()
+		kd.Status.HealthySince = &now
+	}
+
+	remaining := r.StabilizationPeriod - time.Since(kd.Status.HealthySince.Time)
+	if remaining > 0 {
+		message := fmt.Sprintf("KServe deployment is recovering: healthy since %s, reporting Ready after %s",
+			kd.Status.HealthySince.UTC().Format(time.RFC3339), r.StabilizationPeriod)
+		return "Degraded", message, remaining
+	}
+
+	kd.Status.HealthySince = nil
+	r.recordEvent(kd, corev1.EventTypeNormal, "Recovered",
+		fmt.Sprintf("KServe deployment recovered after staying healthy for %s", r.StabilizationPeriod))
+	return "Ready", "", 0
+}
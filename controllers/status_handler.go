@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// StatusHandler serves the status of every KServeDeployment as JSON, for
+// portals that shouldn't need Kubernetes API access. It has no
+// authentication of its own: main mounts it on the metrics server only when
+// --serve-status is set, and it is then exposed exactly as far as /metrics is.
+type StatusHandler struct {
+	// Reader lists KServeDeployments; it is set once the manager exists
+	Reader client.Reader
+}
+
+// deploymentStatus is the JSON view of one KServeDeployment
+type deploymentStatus struct {
+	Name             string                             `json:"name"`
+	Namespace        string                             `json:"namespace"`
+	Phase            string                             `json:"phase,omitempty"`
+	DesiredVersion   string                             `json:"desiredVersion"`
+	InstalledVersion string                             `json:"installedVersion,omitempty"`
+	Components       []platformv1alpha1.ComponentStatus `json:"components,omitempty"`
+	Conditions       []metav1.Condition                 `json:"conditions,omitempty"`
+}
+
+func (h *StatusHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.Reader == nil {
+		http.Error(w, "not ready", http.StatusServiceUnavailable)
+		return
+	}
+
+	list := &platformv1alpha1.KServeDeploymentList{}
+	if err := h.Reader.List(req.Context(), list); err != nil {
+		log.FromContext(req.Context()).Error(err, "Failed to list KServeDeployments for the status endpoint")
+		http.Error(w, "failed to list KServeDeployments", http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]deploymentStatus, 0, len(list.Items))
+	for _, kd := range list.Items {
+		items = append(items, deploymentStatus{
+			Name:             kd.Name,
+			Namespace:        kd.Namespace,
+			Phase:            kd.Status.Phase,
+			DesiredVersion:   kd.Spec.Version,
+			InstalledVersion: kd.Status.InstalledVersion,
+			Components:       kd.Status.ComponentStatuses,
+			Conditions:       kd.Status.Conditions,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{"items": items}); err != nil {
+		log.FromContext(req.Context()).Error(err, "Failed to write status response")
+	}
+}
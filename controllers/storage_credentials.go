@@ -0,0 +1,260 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// S3 settings KServe's storage initializer reads from the annotations of the
+// Secrets referenced by an InferenceService's ServiceAccount
+const (
+	s3EndpointAnnotation  = "serving.kserve.io/s3-endpoint"
+	s3RegionAnnotation    = "serving.kserve.io/s3-region"
+	s3UseHTTPSAnnotation  = "serving.kserve.io/s3-usehttps"
+	s3VerifySSLAnnotation = "serving.kserve.io/s3-verifyssl"
+)
+
+// storageServiceAccountName is the ServiceAccount InferenceServices pull
+// their models with
+func storageServiceAccountName(kd *platformv1alpha1.KServeDeployment) string {
+	if name := kd.Spec.StorageCredentials.ServiceAccountName; name != "" {
+		return name
+	}
+	return kd.Name + "-storage"
+}
+
+// storageSecretName is the operator's copy of the storage credentials Secret
+func storageSecretName(kd *platformv1alpha1.KServeDeployment) string {
+	return storageServiceAccountName(kd) + "-credentials"
+}
+
+// useStorageServiceAccount returns a mutator that sets name as the
+// ServiceAccount of InferenceService predictors that don't name one
+func useStorageServiceAccount(name string) objectMutator {
+	return func(obj *unstructured.Unstructured) error {
+		if !isInferenceService(obj) {
+			return nil
+		}
+
+		current, _, err := unstructured.NestedString(obj.Object, "spec", "predictor", "serviceAccountName")
+		if err != nil || current != "" {
+			return err
+		}
+		return unstructured.SetNestedField(obj.Object, name, "spec", "predictor", "serviceAccountName")
+	}
+}
+
+// storageNamespaces are the namespaces the rendered InferenceServices run
+// in, plus the KServeDeployment's own. They are taken from the manifests about
+// to be applied, so an InferenceService's ServiceAccount exists before the
+// InferenceService does. Namespaces outside AllowedNamespaces are an error.
+func (r *KServeDeploymentReconciler) storageNamespaces(ctx context.Context, kd *platformv1alpha1.KServeDeployment) ([]string, error) {
+	namespaces := []string{kd.Namespace}
+
+	rendered, err := r.renderObjects(ctx, kd)
+	if err != nil {
+		// The install itself reports why the manifests can't be applied; keep
+		// the credentials where they already are until then
+		log.FromContext(ctx).V(1).Info("Keeping storage credential namespaces, manifests did not render", "error", err.Error())
+		if kd.Status.StorageCredentials != nil {
+			namespaces = append(namespaces, kd.Status.StorageCredentials.Namespaces...)
+		}
+	}
+	for i := range rendered {
+		if isInferenceService(&rendered[i]) && rendered[i].GetNamespace() != "" {
+			namespaces = append(namespaces, rendered[i].GetNamespace())
+		}
+	}
+	sort.Strings(namespaces)
+	namespaces = uniqueStrings(namespaces)
+
+	allowed := map[string]bool{kd.Namespace: true}
+	for _, ns := range kd.Spec.StorageCredentials.AllowedNamespaces {
+		allowed[ns] = true
+	}
+	denied := []string{}
+	for _, ns := range namespaces {
+		if !allowed[ns] {
+			denied = append(denied, ns)
+		}
+	}
+	if len(denied) > 0 {
+		return nil, withReason(platformv1alpha1.ReasonProvisioningFailed,
+			fmt.Errorf("InferenceServices run in %s, which storageCredentials.allowedNamespaces doesn't list", strings.Join(denied, ", ")))
+	}
+	return namespaces, nil
+}
+
+// ensureStorageCredentials provisions the storage credentials Secret and
+// ServiceAccount in every namespace InferenceServices are about to be applied
+// to and reports the outcome
+// in status and the StorageCredentialsReady condition. Both are recorded as
+// managed resources, so they are pruned when StorageCredentials is removed
+// and deleted with the KServeDeployment.
+func (r *KServeDeploymentReconciler) ensureStorageCredentials(ctx context.Context, kd *platformv1alpha1.KServeDeployment) error {
+	if kd.Spec.StorageCredentials == nil {
+		kd.Status.StorageCredentials = nil
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "StorageCredentialsReady")
+		return nil
+	}
+
+	namespaces, err := r.storageNamespaces(ctx, kd)
+	if err == nil {
+		err = r.provisionStorageCredentials(ctx, kd, namespaces)
+	}
+	if err != nil {
+		meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+			Type:               "StorageCredentialsReady",
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: kd.Generation,
+			Reason:             failureReason(err),
+			Message:            err.Error(),
+		})
+		return err
+	}
+
+	kd.Status.StorageCredentials = &platformv1alpha1.StorageCredentialsStatus{
+		ServiceAccountName: storageServiceAccountName(kd),
+		SecretName:         storageSecretName(kd),
+		Namespaces:         namespaces,
+	}
+	meta.SetStatusCondition(&kd.Status.Conditions, metav1.Condition{
+		Type:               "StorageCredentialsReady",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kd.Generation,
+		Reason:             platformv1alpha1.ReasonCredentialsProvisioned,
+		Message:            fmt.Sprintf("ServiceAccount %s provisioned in %s", storageServiceAccountName(kd), strings.Join(namespaces, ", ")),
+	})
+	return nil
+}
+
+// provisionStorageCredentials copies the referenced Secret into each
+// namespace with the S3 annotations and creates the ServiceAccount using it
+func (r *KServeDeploymentReconciler) provisionStorageCredentials(ctx context.Context, kd *platformv1alpha1.KServeDeployment, namespaces []string) error {
+	creds := kd.Spec.StorageCredentials
+
+	source := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: kd.Namespace, Name: creds.SecretName}, source); err != nil {
+		if errors.IsNotFound(err) {
+			return withReason(platformv1alpha1.ReasonDependencyMissing,
+				fmt.Errorf("storage credentials secret %s not found in %s", creds.SecretName, kd.Namespace))
+		}
+		return withReason(platformv1alpha1.ReasonProvisioningFailed,
+			fmt.Errorf("failed to get storage credentials secret %s/%s: %w", kd.Namespace, creds.SecretName, err))
+	}
+
+	for _, ns := range namespaces {
+		if err := r.writeStorageSecret(ctx, kd, source, ns); err != nil {
+			return withReason(platformv1alpha1.ReasonProvisioningFailed, err)
+		}
+		if err := r.writeStorageServiceAccount(ctx, kd, ns); err != nil {
+			return withReason(platformv1alpha1.ReasonProvisioningFailed, err)
+		}
+	}
+	return nil
+}
+
+// writeStorageSecret creates or refreshes the copy of source in namespace
+func (r *KServeDeploymentReconciler) writeStorageSecret(ctx context.Context, kd *platformv1alpha1.KServeDeployment, source *corev1.Secret, namespace string) error {
+	secret := &corev1.Secret{}
+	secret.Name = storageSecretName(kd)
+	secret.Namespace = namespace
+
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, secret, func() error {
+		if secret.ResourceVersion != "" && !ownedBy(secret.Labels, kd) {
+			return fmt.Errorf("secret %s/%s exists and was not created by this KServeDeployment", namespace, secret.Name)
+		}
+		secret.Labels = map[string]string{
+			ownerNameLabel:      kd.Name,
+			ownerNamespaceLabel: kd.Namespace,
+			watchLabel:          "true",
+		}
+		secret.Annotations = s3Annotations(kd.Spec.StorageCredentials.S3)
+		secret.Type = source.Type
+		secret.Data = source.Data
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write storage credentials secret to %s: %w", namespace, err)
+	}
+	if result != controllerutil.OperationResultNone {
+		log.FromContext(ctx).Info("Provisioned storage credentials secret", "secret", secret.Name, "namespace", namespace, "operation", result)
+	}
+	recordApplied(ctx, platformv1alpha1.ResourceRef{APIVersion: "v1", Kind: "Secret", Namespace: namespace, Name: secret.Name})
+	return nil
+}
+
+// writeStorageServiceAccount creates the ServiceAccount referencing the
+// storage credentials Secret in namespace
+func (r *KServeDeploymentReconciler) writeStorageServiceAccount(ctx context.Context, kd *platformv1alpha1.KServeDeployment, namespace string) error {
+	sa := &corev1.ServiceAccount{}
+	sa.Name = storageServiceAccountName(kd)
+	sa.Namespace = namespace
+
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, sa, func() error {
+		if sa.ResourceVersion != "" && !ownedBy(sa.Labels, kd) {
+			return fmt.Errorf("ServiceAccount %s/%s exists and was not created by this KServeDeployment", namespace, sa.Name)
+		}
+		sa.Labels = map[string]string{
+			ownerNameLabel:      kd.Name,
+			ownerNamespaceLabel: kd.Namespace,
+		}
+		for _, ref := range sa.Secrets {
+			if ref.Name == storageSecretName(kd) {
+				return nil
+			}
+		}
+		sa.Secrets = append(sa.Secrets, corev1.ObjectReference{Name: storageSecretName(kd)})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write storage ServiceAccount to %s: %w", namespace, err)
+	}
+	if result != controllerutil.OperationResultNone {
+		log.FromContext(ctx).Info("Provisioned storage ServiceAccount", "serviceAccount", sa.Name, "namespace", namespace, "operation", result)
+	}
+	recordApplied(ctx, platformv1alpha1.ResourceRef{APIVersion: "v1", Kind: "ServiceAccount", Namespace: namespace, Name: sa.Name})
+	return nil
+}
+
+// s3Annotations are the storage initializer annotations for s3
+func s3Annotations(s3 *platformv1alpha1.S3StorageSpec) map[string]string {
+	if s3 == nil {
+		return nil
+	}
+	annotations := map[string]string{}
+	if s3.Endpoint != "" {
+		annotations[s3EndpointAnnotation] = s3.Endpoint
+	}
+	if s3.Region != "" {
+		annotations[s3RegionAnnotation] = s3.Region
+	}
+	if s3.UseHTTPS != nil {
+		annotations[s3UseHTTPSAnnotation] = boolFlag(*s3.UseHTTPS)
+	}
+	if s3.VerifySSL != nil {
+		annotations[s3VerifySSLAnnotation] = boolFlag(*s3.VerifySSL)
+	}
+	return annotations
+}
+
+// boolFlag formats b the way KServe's S3 annotations expect
+func boolFlag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
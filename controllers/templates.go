@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// inferenceServiceTemplateDir holds the templates a spec can name. Paths
+// outside it are refused, so a KServeDeployment can't read arbitrary files
+// from the operator's filesystem.
+const inferenceServiceTemplateDir = "config/operand"
+
+// defaultInferenceServiceTemplate is used when the spec doesn't name a template
+const defaultInferenceServiceTemplate = inferenceServiceTemplateDir + "/gemma2-inferenceservice.yaml"
+
+// inferenceServiceTemplateData is what InferenceService templates can reference
+type inferenceServiceTemplateData struct {
+	// Name and Namespace of the KServeDeployment
+	Name      string
+	Namespace string
+
+	// KServeNamespace is where KServe itself is installed
+	KServeNamespace string
+
+	// Version of KServe being deployed
+	Version string
+
+	// IngressDomain from the KServe config, if set
+	IngressDomain string
+
+	// Parameters are the free-form template parameters from the spec
+	Parameters map[string]string
+
+	// Spec is the full KServeDeployment spec
+	Spec platformv1alpha1.KServeDeploymentSpec
+}
+
+// inferenceServiceTemplatePath returns the template to render for kd
+func inferenceServiceTemplatePath(kd *platformv1alpha1.KServeDeployment) string {
+	if kd.Spec.InferenceService != nil && kd.Spec.InferenceService.TemplatePath != "" {
+		return kd.Spec.InferenceService.TemplatePath
+	}
+	return defaultInferenceServiceTemplate
+}
+
+// checkTemplatePath rejects template paths outside inferenceServiceTemplateDir
+func checkTemplatePath(path string) error {
+	rel, err := filepath.Rel(inferenceServiceTemplateDir, filepath.Clean(path))
+	if err != nil || filepath.IsAbs(path) || rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return fmt.Errorf("InferenceService template %s must be in %s", path, inferenceServiceTemplateDir)
+	}
+	return nil
+}
+
+// renderInferenceServiceTemplate executes the InferenceService template at
+// path against kd's spec
+func renderInferenceServiceTemplate(kd *platformv1alpha1.KServeDeployment, path string) ([]byte, error) {
+	if err := checkTemplatePath(path); err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read InferenceService template: %w", err)
+	}
+
+	tmpl, err := template.New(path).Option("missingkey=zero").Parse(string(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse InferenceService template %s: %w", path, err)
+	}
+
+	data := inferenceServiceTemplateData{
+		Name:            kd.Name,
+		Namespace:       kd.Namespace,
+		KServeNamespace: componentNamespace(kd, "kserve"),
+		Version:         kd.Spec.Version,
+		Parameters:      map[string]string{},
+		Spec:            kd.Spec,
+	}
+	if kd.Spec.Config != nil {
+		data.IngressDomain = kd.Spec.Config.IngressDomain
+	}
+	if kd.Spec.InferenceService != nil {
+		for k, v := range kd.Spec.InferenceService.Parameters {
+			data.Parameters[k] = v
+		}
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("failed to render InferenceService template %s: %w", path, err)
+	}
+	return out.Bytes(), nil
+}
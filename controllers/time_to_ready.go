@@ -0,0 +1,36 @@
+package controllers
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// startInstallTimer starts timing an install on the first reconcile of the
+// object and restarts it when the spec changed since the last reconcile.
+// Objects that were installed before the timer existed aren't timed until
+// their next spec change.
+func startInstallTimer(kd *platformv1alpha1.KServeDeployment) {
+	changed := kd.Status.ObservedSpecHash != "" && kd.Status.ObservedSpecHash != specHash(kd)
+	if !changed && (kd.Status.InstallStartedAt != nil || kd.Status.Phase != "") {
+		return
+	}
+
+	now := metav1.Now()
+	kd.Status.InstallStartedAt = &now
+	kd.Status.ReadyDuration = nil
+}
+
+// recordTimeToReady records how long the current install took the first time
+// it reports Ready
+func recordTimeToReady(kd *platformv1alpha1.KServeDeployment, phase string) {
+	if phase != "Ready" || kd.Status.InstallStartedAt == nil || kd.Status.ReadyDuration != nil {
+		return
+	}
+
+	elapsed := time.Since(kd.Status.InstallStartedAt.Time)
+	kd.Status.ReadyDuration = &metav1.Duration{Duration: elapsed}
+	observeTimeToReady(elapsed)
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// upgradeCheckpoint returns the checkpoint for an install or upgrade towards
+// Spec.Version, starting a fresh one when none exists or the desired version
+// changed mid-upgrade. It returns nil once the desired version is installed.
+func upgradeCheckpoint(kd *platformv1alpha1.KServeDeployment) *platformv1alpha1.UpgradeCheckpoint {
+	if kd.Status.InstalledVersion == kd.Spec.Version {
+		kd.Status.UpgradeCheckpoint = nil
+		return nil
+	}
+
+	checkpoint := kd.Status.UpgradeCheckpoint
+	if checkpoint == nil || checkpoint.TargetVersion != kd.Spec.Version {
+		checkpoint = &platformv1alpha1.UpgradeCheckpoint{
+			TargetVersion: kd.Spec.Version,
+			StartedAt:     metav1.Now(),
+		}
+		kd.Status.UpgradeCheckpoint = checkpoint
+	}
+
+	return checkpoint
+}
+
+// checkpointCompleted reports whether component was already upgraded
+func checkpointCompleted(checkpoint *platformv1alpha1.UpgradeCheckpoint, component string) bool {
+	if checkpoint == nil {
+		return false
+	}
+	for _, c := range checkpoint.CompletedComponents {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+// saveCheckpoint marks component as upgraded and persists the checkpoint so a
+// restarted operator resumes from here instead of starting over
+func (r *KServeDeploymentReconciler) saveCheckpoint(ctx context.Context, kd *platformv1alpha1.KServeDeployment, component string) error {
+	checkpoint := kd.Status.UpgradeCheckpoint
+	if checkpoint == nil {
+		return nil
+	}
+
+	checkpoint.CompletedComponents = append(checkpoint.CompletedComponents, component)
+	kd.Status.Phase = "Installing"
+
+	log.FromContext(ctx).Info("Saving upgrade checkpoint",
+		"targetVersion", checkpoint.TargetVersion,
+		"completed", checkpoint.CompletedComponents)
+
+	if err := r.Status().Update(ctx, kd); err != nil {
+		return fmt.Errorf("failed to save upgrade checkpoint: %w", err)
+	}
+	return nil
+}
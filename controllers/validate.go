@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"context"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// ValidationReport is the outcome of validating a KServeDeployment without
+// applying it
+type ValidationReport struct {
+	// Name and Namespace of the KServeDeployment
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+
+	// SpecErrors are the spec's validation errors, as the webhook reports them
+	SpecErrors []string `json:"specErrors,omitempty"`
+
+	// RenderError is why the manifests couldn't be rendered, e.g. a manifest
+	// that can't be fetched or a missing default runtime
+	RenderError string `json:"renderError,omitempty"`
+
+	// Resources lists the objects that would be applied
+	Resources []string `json:"resources,omitempty"`
+
+	// SchemaFindings are what the cluster rejected in a dry run
+	SchemaFindings []string `json:"schemaFindings,omitempty"`
+}
+
+// Valid reports whether the operator would apply the KServeDeployment
+// without spec, render or schema errors
+func (v *ValidationReport) Valid() bool {
+	return len(v.SpecErrors) == 0 && v.RenderError == "" && len(v.SchemaFindings) == 0
+}
+
+// Validate checks the spec, renders the manifests kd would apply and
+// dry-runs them against the cluster. It goes through the same fetch, decode
+// and mutate pipeline as a reconcile and persists nothing. Schema
+// validation runs whatever kd's ManifestValidation mode is. The error is
+// only set when a dry run fails for another reason than the objects
+// themselves; problems with kd itself are in the report.
+func (r *KServeDeploymentReconciler) Validate(ctx context.Context, kd *platformv1alpha1.KServeDeployment) (*ValidationReport, error) {
+	report := &ValidationReport{Name: kd.Name, Namespace: kd.Namespace}
+
+	for _, err := range kd.ValidateSpec() {
+		report.SpecErrors = append(report.SpecErrors, err.Error())
+	}
+	if len(report.SpecErrors) > 0 {
+		return report, nil
+	}
+
+	rendered, err := r.renderObjects(ctx, kd)
+	if err != nil {
+		report.RenderError = err.Error()
+		return report, nil
+	}
+	for i := range rendered {
+		report.Resources = append(report.Resources, objectID(&rendered[i]))
+	}
+
+	findings, err := r.schemaFindings(ctx, rendered)
+	if err != nil {
+		return report, err
+	}
+	report.SchemaFindings = findings
+	return report, nil
+}
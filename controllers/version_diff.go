@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// diffVersionAnnotation requests a comparison between the installed KServe
+// version and the version it names, e.g. "v0.12.0". Nothing is applied.
+const diffVersionAnnotation = "platform.ai-platform.io/diff-version"
+
+// kserveManifestURL is the release manifest for a KServe version
+func kserveManifestURL(version string) string {
+	return fmt.Sprintf("https://github.com/kserve/kserve/releases/download/%s/kserve.yaml", version)
+}
+
+// reconcileVersionDiff computes the diff requested by the diff-version
+// annotation, keeping the previous result while the versions are unchanged
+// and clearing it once the annotation is removed
+func (r *KServeDeploymentReconciler) reconcileVersionDiff(ctx context.Context, kd *platformv1alpha1.KServeDeployment) {
+	toVersion := kd.Annotations[diffVersionAnnotation]
+	if toVersion == "" {
+		kd.Status.VersionDiff = nil
+		return
+	}
+
+	fromVersion := kd.Status.InstalledVersion
+	if previous := kd.Status.VersionDiff; previous != nil && previous.Error == "" &&
+		previous.FromVersion == fromVersion && previous.ToVersion == toVersion {
+		return
+	}
+
+	log.FromContext(ctx).Info("Computing KServe version diff", "from", fromVersion, "to", toVersion)
+	diff, err := r.diffVersions(ctx, fromVersion, toVersion)
+	if err != nil {
+		diff = &platformv1alpha1.VersionDiff{FromVersion: fromVersion, ToVersion: toVersion, Error: err.Error()}
+	}
+	diff.GeneratedAt = metav1.Now()
+	kd.Status.VersionDiff = diff
+}
+
+// diffVersions compares the KServe release manifests of two versions
+func (r *KServeDeploymentReconciler) diffVersions(ctx context.Context, fromVersion, toVersion string) (*platformv1alpha1.VersionDiff, error) {
+	if fromVersion == "" {
+		return nil, fmt.Errorf("no KServe version is installed yet")
+	}
+
+	from, err := r.manifestHashes(ctx, kserveManifestURL(fromVersion))
+	if err != nil {
+		return nil, fmt.Errorf("version %s: %w", fromVersion, err)
+	}
+	to, err := r.manifestHashes(ctx, kserveManifestURL(toVersion))
+	if err != nil {
+		return nil, fmt.Errorf("version %s: %w", toVersion, err)
+	}
+
+	diff := &platformv1alpha1.VersionDiff{FromVersion: fromVersion, ToVersion: toVersion}
+	for ref, hash := range to {
+		fromHash, ok := from[ref]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, ref)
+		case fromHash != hash:
+			diff.Changed = append(diff.Changed, ref)
+		}
+	}
+	for ref := range from {
+		if _, ok := to[ref]; !ok {
+			diff.Removed = append(diff.Removed, ref)
+		}
+	}
+
+	sortResourceRefs(diff.Added)
+	sortResourceRefs(diff.Removed)
+	sortResourceRefs(diff.Changed)
+	return diff, nil
+}
+
+// manifestHashes fetches and decodes a manifest, keying each resource's
+// content hash by its reference
+func (r *KServeDeploymentReconciler) manifestHashes(ctx context.Context, url string) (map[platformv1alpha1.ResourceRef]string, error) {
+	manifestBytes, err := r.fetchManifest(ctx, url, "")
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := map[platformv1alpha1.ResourceRef]string{}
+	for _, obj := range decodeManifests(ctx, manifestBytes) {
+		obj := obj
+		hash, err := contentHash(&obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		hashes[resourceRefFor(&obj)] = hash
+	}
+	return hashes, nil
+}
+
+func sortResourceRefs(refs []platformv1alpha1.ResourceRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		a, b := refs[i], refs[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+// webhookProbeInterval is how often an unreachable KServe webhook is rechecked
+const webhookProbeInterval = 15 * time.Second
+
+// kserveWebhookKinds are the kinds KServe's admission webhooks validate. They
+// can't be created while the webhooks don't serve.
+var kserveWebhookKinds = map[string]bool{
+	"InferenceService": true,
+	"InferenceGraph":   true,
+	"TrainedModel":     true,
+}
+
+// WebhookUnreachableError is returned while a manifest waits for KServe's
+// admission webhook to serve
+type WebhookUnreachableError struct {
+	Err error
+}
+
+func (e *WebhookUnreachableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *WebhookUnreachableError) Unwrap() error {
+	return e.Err
+}
+
+// asWebhookUnreachable reports whether err was caused by KServe's admission
+// webhook not serving yet
+func asWebhookUnreachable(err error) (*WebhookUnreachableError, bool) {
+	var unreachable *WebhookUnreachableError
+	ok := stderrors.As(err, &unreachable)
+	return unreachable, ok
+}
+
+// kserveWebhookGate remembers the outcome of probing KServe's webhook during
+// one pass over the extra manifests
+type kserveWebhookGate struct {
+	probed bool
+	err    error
+}
+
+// setCondition records the probe in the WebhookReachable condition, or drops
+// the condition when nothing needed the webhook
+func (g *kserveWebhookGate) setCondition(kd *platformv1alpha1.KServeDeployment) {
+	if !g.probed {
+		meta.RemoveStatusCondition(&kd.Status.Conditions, "WebhookReachable")
+		return
+	}
+	setWebhookCondition(kd, g.err)
+}
+
+// awaitKServeWebhook returns a WebhookUnreachableError when manifest holds
+// resources KServe's admission webhooks validate and the webhook doesn't
+// serve. Only deployments installing KServe are checked, and manifests
+// without such resources never wait.
+func (r *KServeDeploymentReconciler) awaitKServeWebhook(ctx context.Context, kd *platformv1alpha1.KServeDeployment, gate *kserveWebhookGate, manifest []byte) error {
+	if !hasComponent(kd, "kserve") {
+		return nil
+	}
+	namespace, ok := kserveWebhookNamespace(ctx, kd, manifest)
+	if !ok {
+		return nil
+	}
+
+	if !gate.probed {
+		gate.probed = true
+		gate.err = r.probeKServeWebhook(ctx, kd, namespace)
+	}
+	if gate.err != nil {
+		return &WebhookUnreachableError{Err: gate.err}
+	}
+	return nil
+}
+
+// kserveWebhookNamespace returns the namespace of the first resource in
+// manifest that KServe's admission webhooks validate, and false when there is
+// none
+func kserveWebhookNamespace(ctx context.Context, kd *platformv1alpha1.KServeDeployment, manifest []byte) (string, bool) {
+	for _, obj := range decodeManifests(ctx, manifest) {
+		obj := obj
+		if obj.GroupVersionKind().Group == "serving.kserve.io" && kserveWebhookKinds[obj.GetKind()] {
+			return inferenceServiceNamespace(kd, &obj), true
+		}
+	}
+	return "", false
+}
+
+// probeKServeWebhook dry-run creates an InferenceService in namespace, which
+// passes through KServe's admission webhooks without persisting anything. A
+// rejection of the probe object by the webhooks still proves they serve. Any
+// other failure is returned, including the operator not being permitted to
+// create InferenceServices there.
+func (r *KServeDeploymentReconciler) probeKServeWebhook(ctx context.Context, kd *platformv1alpha1.KServeDeployment, namespace string) error {
+	probe := &unstructured.Unstructured{}
+	probe.SetAPIVersion("serving.kserve.io/v1beta1")
+	probe.SetKind("InferenceService")
+	probe.SetNamespace(namespace)
+	probe.SetName(kd.Name + "-webhook-probe")
+	probe.Object["spec"] = map[string]interface{}{
+		"predictor": map[string]interface{}{
+			"model": map[string]interface{}{
+				"modelFormat": map[string]interface{}{"name": "sklearn"},
+				"storageUri":  "gs://kfserving-examples/models/sklearn/1.0/model",
+			},
+		},
+	}
+
+	err := r.Create(ctx, probe, client.DryRunAll)
+	switch {
+	case err == nil, errors.IsAlreadyExists(err), errors.IsInvalid(err), deniedByWebhook(err):
+		// Admission ran; AlreadyExists is only reported after the webhooks pass
+		return nil
+	case errors.IsForbidden(err):
+		return fmt.Errorf("the operator may not create InferenceServices in %s: %w", namespace, err)
+	case meta.IsNoMatchError(err):
+		return fmt.Errorf("InferenceService CRD is not installed yet")
+	default:
+		// Typically "failed calling webhook": no ready endpoints or TLS not set up yet
+		return fmt.Errorf("KServe admission webhook is not reachable: %w", err)
+	}
+}
+
+// deniedByWebhook reports whether err is an admission webhook rejecting the
+// request, which the API server reports as Forbidden like an RBAC denial
+func deniedByWebhook(err error) bool {
+	return errors.IsForbidden(err) && strings.Contains(err.Error(), "admission webhook") &&
+		strings.Contains(err.Error(), "denied the request")
+}
+
+// setWebhookCondition records the result of probing the KServe webhook
+func setWebhookCondition(kd *platformv1alpha1.KServeDeployment, probeErr error) {
+	condition := metav1.Condition{
+		Type:               "WebhookReachable",
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: kd.Generation,
+		Reason:             platformv1alpha1.ReasonDryRunSucceeded,
+		Message:            "KServe admission webhook accepted a dry-run InferenceService",
+	}
+	if probeErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = platformv1alpha1.ReasonDryRunFailed
+		condition.Message = probeErr.Error()
+	}
+	meta.SetStatusCondition(&kd.Status.Conditions, condition)
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+)
+
+const inferenceServiceManifest = `apiVersion: serving.kserve.io/v1beta1
+kind: InferenceService
+metadata:
+  name: sklearn
+  namespace: models
+`
+
+func TestAwaitKServeWebhookProbesOnlyWhereNeeded(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	isvcs := schema.GroupResource{Group: "serving.kserve.io", Resource: "inferenceservices"}
+	var probed []string
+	c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+			probed = append(probed, obj.GetNamespace())
+			return errors.NewForbidden(isvcs, obj.GetName(), nil)
+		},
+	}).Build()
+	r := &KServeDeploymentReconciler{Client: c, Scheme: scheme}
+
+	kd := &platformv1alpha1.KServeDeployment{ObjectMeta: metav1.ObjectMeta{Name: "kserve", Namespace: "platform"}}
+	kd.Spec.Components = []string{"kserve"}
+	ctx := context.Background()
+	gate := kserveWebhookGate{}
+
+	configMap := []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n")
+	if err := r.awaitKServeWebhook(ctx, kd, &gate, configMap); err != nil {
+		t.Fatalf("a manifest without webhook-validated kinds waited: %v", err)
+	}
+	if len(probed) != 0 {
+		t.Fatalf("probed for a manifest without webhook-validated kinds: %v", probed)
+	}
+
+	for i := 0; i < 2; i++ {
+		err := r.awaitKServeWebhook(ctx, kd, &gate, []byte(inferenceServiceManifest))
+		if _, ok := asWebhookUnreachable(err); !ok {
+			t.Fatalf("a Forbidden probe was not reported as unreachable: %v", err)
+		}
+	}
+	if len(probed) != 1 || probed[0] != "models" {
+		t.Fatalf("expected one probe in the InferenceService's namespace, got %v", probed)
+	}
+}
+
+func TestWebhookDenialCountsAsReachable(t *testing.T) {
+	denied := errors.NewForbidden(schema.GroupResource{Group: "serving.kserve.io", Resource: "inferenceservices"}, "probe",
+		stderrors.New(`admission webhook "inferenceservice.kserve-webhook-server.validator" denied the request: invalid`))
+	if !deniedByWebhook(denied) {
+		t.Fatal("a webhook denial was not recognised")
+	}
+	if deniedByWebhook(errors.NewForbidden(schema.GroupResource{Resource: "inferenceservices"}, "probe", nil)) {
+		t.Fatal("an RBAC denial was taken for a webhook denial")
+	}
+}
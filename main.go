@@ -1,21 +1,35 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
 	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+	platformv1beta1 "github.com/jamesdhope/ai-platform/api/v1beta1"
 	"github.com/jamesdhope/ai-platform/controllers"
 )
 
 var (
+	// version is the operator build version, set with -ldflags "-X main.version=..."
+	version = "dev"
+
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
 )
@@ -23,25 +37,99 @@ var (
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(platformv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(platformv1beta1.AddToScheme(scheme))
 }
 
 func main() {
+	// `operator validate` checks KServeDeployments instead of running the controller
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(runValidate(os.Args[2:]))
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var enableWebhooks bool
+	var watchNamespace string
+	var sourceFailureThreshold int
+	var sourceCooldown time.Duration
+	var slowSourceFactor float64
+	var retryBudget int
+	var syncPeriod time.Duration
+	var stabilizationPeriod time.Duration
+	var canaryPeriod time.Duration
+	var manifestCacheDir string
+	var manifestCacheTTL time.Duration
+	var pauseConfigMap string
+	var tracingEndpoint string
+	var serveStatus bool
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false, "Enable leader election for controller manager.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", os.Getenv("ENABLE_WEBHOOKS") == "true",
+		"Serve the KServeDeployment validating and conversion webhooks (requires serving certificates; defaults to $ENABLE_WEBHOOKS).")
+	flag.StringVar(&watchNamespace, "watch-namespace", os.Getenv("WATCH_NAMESPACE"),
+		"Restrict the operator to a single namespace (defaults to $WATCH_NAMESPACE; empty watches all namespaces).")
+	flag.IntVar(&sourceFailureThreshold, "source-failure-threshold", 3, "Consecutive fetch failures before a manifest source's circuit opens.")
+	flag.DurationVar(&syncPeriod, "sync-period", 10*time.Hour,
+		"How often every KServeDeployment is re-reconciled to heal drift such as a deleted component namespace.")
+	flag.DurationVar(&stabilizationPeriod, "stabilization-period", 2*time.Minute,
+		"How long a Degraded deployment must stay healthy before it is reported Ready again.")
+	flag.DurationVar(&canaryPeriod, "canary-period", time.Hour,
+		"How long canary KServeDeployments must be Ready at a new version before the others are upgraded to it.")
+	flag.DurationVar(&sourceCooldown, "source-cooldown", 5*time.Minute, "How long an open manifest source circuit waits before probing again.")
+	flag.IntVar(&retryBudget, "retry-budget", 100,
+		"Apply retries the operator may spend per minute across all KServeDeployments before deferring them (0 disables the budget).")
+	flag.Float64Var(&slowSourceFactor, "slow-source-factor", 3,
+		"Multiple of a manifest source's average fetch latency that sets the SlowManifestSource condition (0 disables it).")
+
+	flag.StringVar(&manifestCacheDir, "manifest-cache-dir", os.Getenv("MANIFEST_CACHE_DIR"),
+		"Directory (e.g. a PVC mount) to cache fetched manifests in (defaults to $MANIFEST_CACHE_DIR; empty disables the cache).")
+	flag.DurationVar(&manifestCacheTTL, "manifest-cache-ttl", time.Hour, "How long a cached manifest is served before it is fetched again.")
+	flag.StringVar(&pauseConfigMap, "pause-configmap", "",
+		"namespace/name of the ConfigMap whose presence pauses all reconciles (defaults to ai-platform-operator-pause in the watch namespace, or ai-platform-system).")
+	flag.StringVar(&tracingEndpoint, "tracing-endpoint", "",
+		"OTLP/HTTP collector URL to export reconcile traces to, e.g. http://otel-collector:4318 (empty disables tracing).")
+	flag.BoolVar(&serveStatus, "serve-status", false,
+		"Serve the status of every KServeDeployment at /status on the metrics server. It is unauthenticated, so only enable it where the metrics port is restricted.")
 
 	opts := zap.Options{Development: true}
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	setupLog.Info("starting operator", "version", version)
+
+	pauseKey, err := parsePauseConfigMap(pauseConfigMap, watchNamespace)
+	if err != nil {
+		setupLog.Error(err, "invalid --pause-configmap")
+		os.Exit(1)
+	}
+
+	cacheOpts := cache.Options{
+		SyncPeriod: &syncPeriod,
+		ByObject:   controllers.CacheByObject(),
+	}
+	if watchNamespace != "" {
+		setupLog.Info("restricting operator to a single namespace", "namespace", watchNamespace)
+		cacheOpts.DefaultNamespaces = map[string]cache.Config{watchNamespace: {}}
+	}
+
+	// /status is served next to /metrics, without authentication, so it is
+	// only mounted when asked for
+	metricsOpts := metricsserver.Options{BindAddress: metricsAddr}
+	statusHandler := &controllers.StatusHandler{}
+	if serveStatus {
+		setupLog.Info("serving KServeDeployment status on the metrics server", "path", "/status")
+		metricsOpts.ExtraHandlers = map[string]http.Handler{"/status": statusHandler}
+	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cacheOpts,
+		Client:                 client.Options{Cache: &client.CacheOptions{DisableFor: controllers.UncachedObjects()}},
+		Metrics:                metricsOpts,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "kserve-deployment.platform.ai-platform.io",
@@ -51,14 +139,61 @@ func main() {
 		os.Exit(1)
 	}
 
+	statusHandler.Reader = mgr.GetClient()
+
+	var manifestCache *controllers.ManifestCache
+	if manifestCacheDir != "" {
+		setupLog.Info("caching manifests on disk", "dir", manifestCacheDir, "ttl", manifestCacheTTL)
+		if manifestCache, err = controllers.NewManifestCache(manifestCacheDir, manifestCacheTTL); err != nil {
+			setupLog.Error(err, "unable to set up manifest cache")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controllers.KServeDeploymentReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:              mgr.GetClient(),
+		Scheme:              mgr.GetScheme(),
+		APIReader:           mgr.GetAPIReader(),
+		SourceBreaker:       controllers.NewCircuitBreaker(sourceFailureThreshold, sourceCooldown),
+		SourceLatency:       controllers.NewLatencyTracker(slowSourceFactor),
+		RetryBudget:         controllers.NewRetryBudget(retryBudget),
+		ManifestCache:       manifestCache,
+		WatchNamespace:      watchNamespace,
+		OperatorVersion:     version,
+		StabilizationPeriod: stabilizationPeriod,
+		CanaryPeriod:        canaryPeriod,
+		Recorder:            mgr.GetEventRecorderFor("kservedeployment-controller"),
+		PauseConfigMap:      pauseKey,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "KServeDeployment")
 		os.Exit(1)
 	}
 
+	if tracingEndpoint != "" {
+		setupLog.Info("exporting reconcile traces", "endpoint", tracingEndpoint)
+		shutdownTracing, err := controllers.EnableTracing(tracingEndpoint, version)
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+		// Flush the buffered spans when the manager stops
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			<-ctx.Done()
+			return shutdownTracing(context.Background())
+		})); err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+	}
+
+	if enableWebhooks {
+		// Also serves /convert: v1alpha1 is the conversion hub for v1beta1
+		if err = (&platformv1alpha1.KServeDeployment{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "KServeDeployment")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -74,3 +209,21 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// parsePauseConfigMap parses the --pause-configmap flag. The default lives in
+// the watch namespace so the operator's cache can see it in single-namespace mode.
+func parsePauseConfigMap(value, watchNamespace string) (types.NamespacedName, error) {
+	if value == "" {
+		namespace := watchNamespace
+		if namespace == "" {
+			namespace = "ai-platform-system"
+		}
+		return types.NamespacedName{Namespace: namespace, Name: "ai-platform-operator-pause"}, nil
+	}
+
+	namespace, name, ok := strings.Cut(value, "/")
+	if !ok || namespace == "" || name == "" {
+		return types.NamespacedName{}, fmt.Errorf("expected namespace/name, got %q", value)
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, nil
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
+
+	platformv1alpha1 "github.com/jamesdhope/ai-platform/api/v1alpha1"
+	platformv1beta1 "github.com/jamesdhope/ai-platform/api/v1beta1"
+	"github.com/jamesdhope/ai-platform/controllers"
+)
+
+// kserveDeploymentCRD is the CRD the operator is deployed with. Its schema
+// holds the defaults the API server fills in when a KServeDeployment is
+// created.
+//
+//go:embed config/crd/kservedeployment-crd.yaml
+var kserveDeploymentCRD []byte
+
+const validateUsage = "usage: operator validate -f FILE [-o text|json] [--kubeconfig PATH] [--context NAME]"
+
+// runValidate implements `operator validate`. It renders the manifests of
+// each KServeDeployment in a file, dry-runs them against the target cluster
+// and prints a report, without starting the controller. The exit code is 0
+// when every deployment is valid, 1 when one isn't and 2 when validation
+// couldn't run.
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	file := fs.String("f", "", "File holding the KServeDeployments to validate, or - for stdin.")
+	output := fs.String("o", "text", "Report format: text or json.")
+	kubeconfig := fs.String("kubeconfig", "", "kubeconfig of the target cluster (defaults to $KUBECONFIG or ~/.kube/config).")
+	kubeContext := fs.String("context", "", "kubeconfig context to use (defaults to the current context).")
+	timeout := fs.Duration("timeout", 5*time.Minute, "How long fetching and validating may take.")
+	opts := zap.Options{}
+	opts.BindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *file == "" || (*output != "text" && *output != "json") {
+		fmt.Fprintln(os.Stderr, validateUsage)
+		return 2
+	}
+	// Logs go to stderr, keeping stdout for the report
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = *kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{CurrentContext: *kubeContext})
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load kubeconfig: %v\n", err)
+		return 2
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load kubeconfig: %v\n", err)
+		return 2
+	}
+
+	deployments, err := readKServeDeployments(*file, namespace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", *file, err)
+		return 2
+	}
+	if len(deployments) == 0 {
+		fmt.Fprintf(os.Stderr, "%s contains no KServeDeployment\n", *file)
+		return 2
+	}
+
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create client: %v\n", err)
+		return 2
+	}
+
+	// Configured like the running operator, minus the parts that only matter
+	// across reconciles
+	r := &controllers.KServeDeploymentReconciler{
+		Client:          c,
+		Scheme:          scheme,
+		APIReader:       c,
+		SourceBreaker:   controllers.NewCircuitBreaker(3, 5*time.Minute),
+		SourceLatency:   controllers.NewLatencyTracker(0),
+		RetryBudget:     controllers.NewRetryBudget(0),
+		OperatorVersion: version,
+	}
+
+	ctx, cancel := context.WithTimeout(ctrl.SetupSignalHandler(), *timeout)
+	defer cancel()
+	ctx = ctrl.LoggerInto(ctx, ctrl.Log.WithName("validate"))
+
+	reports := []*controllers.ValidationReport{}
+	valid := true
+	for _, kd := range deployments {
+		report, err := r.Validate(ctx, kd)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to validate %s/%s: %v\n", kd.Namespace, kd.Name, err)
+			return 2
+		}
+		reports = append(reports, report)
+		valid = valid && report.Valid()
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(reports); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write report: %v\n", err)
+			return 2
+		}
+	} else {
+		for _, report := range reports {
+			printValidationReport(os.Stdout, report)
+		}
+	}
+
+	if !valid {
+		return 1
+	}
+	return 0
+}
+
+// readKServeDeployments decodes the KServeDeployments in a multi-document
+// YAML file, filling in the CRD's defaults and converting v1beta1 objects to
+// v1alpha1 as the API server and the conversion webhook would. Other kinds
+// are skipped; objects without a namespace get namespace.
+func readKServeDeployments(path, namespace string) ([]*platformv1alpha1.KServeDeployment, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	schemas, err := crdSchemas(kserveDeploymentCRD)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := serializer.NewCodecFactory(scheme).UniversalDeserializer()
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	deployments := []*platformv1alpha1.KServeDeployment{}
+	for {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		if doc, err = withCRDDefaults(doc, schemas); err != nil {
+			return nil, err
+		}
+
+		obj, _, err := decoder.Decode(doc, nil, nil)
+		if runtime.IsNotRegisteredError(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var kd *platformv1alpha1.KServeDeployment
+		switch o := obj.(type) {
+		case *platformv1alpha1.KServeDeployment:
+			kd = o
+		case *platformv1beta1.KServeDeployment:
+			kd = &platformv1alpha1.KServeDeployment{}
+			if err := o.ConvertTo(kd); err != nil {
+				return nil, fmt.Errorf("failed to convert %s to v1alpha1: %w", o.Name, err)
+			}
+		default:
+			continue
+		}
+		if kd.Namespace == "" {
+			kd.Namespace = namespace
+		}
+		deployments = append(deployments, kd)
+	}
+	return deployments, nil
+}
+
+// crdSchemas returns the OpenAPI schema of each version of a CRD, keyed by
+// apiVersion
+func crdSchemas(crd []byte) (map[string]map[string]interface{}, error) {
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal(crd, &obj); err != nil {
+		return nil, fmt.Errorf("failed to read the KServeDeployment CRD: %w", err)
+	}
+	group, _, _ := unstructured.NestedString(obj, "spec", "group")
+	versions, _, _ := unstructured.NestedSlice(obj, "spec", "versions")
+
+	schemas := map[string]map[string]interface{}{}
+	for _, v := range versions {
+		version, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(version, "name")
+		schema, _, _ := unstructured.NestedMap(version, "schema", "openAPIV3Schema")
+		schemas[group+"/"+name] = schema
+	}
+	return schemas, nil
+}
+
+// withCRDDefaults fills in the defaults the schema of doc's apiVersion
+// declares. Documents of other kinds are returned unchanged.
+func withCRDDefaults(doc []byte, schemas map[string]map[string]interface{}) ([]byte, error) {
+	obj := map[string]interface{}{}
+	if err := yaml.Unmarshal(doc, &obj); err != nil {
+		return nil, err
+	}
+	apiVersion, _ := obj["apiVersion"].(string)
+	schema, ok := schemas[apiVersion]
+	if !ok || obj["kind"] != "KServeDeployment" {
+		return doc, nil
+	}
+	applySchemaDefaults(obj, schema)
+	return json.Marshal(obj)
+}
+
+// applySchemaDefaults sets each property missing from value that schema
+// gives a default, walking into nested objects, arrays and maps as the API
+// server's structural defaulting does
+func applySchemaDefaults(value interface{}, schema map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, p := range properties {
+			property, _ := p.(map[string]interface{})
+			if _, set := v[name]; !set {
+				if def, ok := property["default"]; ok {
+					v[name] = runtime.DeepCopyJSONValue(def)
+				}
+			}
+			if child, set := v[name]; set {
+				applySchemaDefaults(child, property)
+			}
+		}
+		if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+			for name, child := range v {
+				if _, declared := properties[name]; !declared {
+					applySchemaDefaults(child, additional)
+				}
+			}
+		}
+	case []interface{}:
+		items, _ := schema["items"].(map[string]interface{})
+		for _, item := range v {
+			applySchemaDefaults(item, items)
+		}
+	}
+}
+
+// printValidationReport writes report for people reading CI logs
+func printValidationReport(w io.Writer, report *controllers.ValidationReport) {
+	if report.Valid() {
+		fmt.Fprintf(w, "KServeDeployment %s/%s: valid, %d resources\n", report.Namespace, report.Name, len(report.Resources))
+		return
+	}
+
+	fmt.Fprintf(w, "KServeDeployment %s/%s: invalid\n", report.Namespace, report.Name)
+	for _, err := range report.SpecErrors {
+		fmt.Fprintf(w, "  spec: %s\n", err)
+	}
+	if report.RenderError != "" {
+		fmt.Fprintf(w, "  render: %s\n", report.RenderError)
+	}
+	for _, finding := range report.SchemaFindings {
+		fmt.Fprintf(w, "  schema: %s\n", finding)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const undefaultedDeployments = `
+apiVersion: platform.ai-platform.io/v1alpha1
+kind: KServeDeployment
+metadata:
+  name: alpha
+spec:
+  version: v0.11.0
+  postInstallJobs:
+    - name: warmup
+      spec:
+        template:
+          spec:
+            containers:
+              - name: warmup
+                image: curlimages/curl:latest
+---
+apiVersion: platform.ai-platform.io/v1beta1
+kind: KServeDeployment
+metadata:
+  name: beta
+  namespace: platform
+spec:
+  version: v0.11.0
+`
+
+func TestValidateAppliesCRDDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deployments.yaml")
+	if err := os.WriteFile(path, []byte(undefaultedDeployments), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	deployments, err := readKServeDeployments(path, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deployments) != 2 {
+		t.Fatalf("read %d deployments, want 2", len(deployments))
+	}
+
+	alpha, beta := deployments[0], deployments[1]
+	if alpha.Namespace != "default" || beta.Namespace != "platform" {
+		t.Errorf("namespaces = %s, %s; want default, platform", alpha.Namespace, beta.Namespace)
+	}
+	for _, kd := range deployments {
+		if kd.Spec.Namespace != "kserve" {
+			t.Errorf("%s: spec.namespace = %q, want the CRD default kserve", kd.Name, kd.Spec.Namespace)
+		}
+		if kd.Spec.ApplyStrategy != "Update" {
+			t.Errorf("%s: spec.applyStrategy = %q, want the CRD default Update", kd.Name, kd.Spec.ApplyStrategy)
+		}
+	}
+
+	job := alpha.Spec.PostInstallJobs[0]
+	if job.RetentionPolicy != "DeleteOnSuccess" || job.TimeoutSeconds == nil || *job.TimeoutSeconds != 600 {
+		t.Errorf("post-install Job = %s, %v; want the CRD defaults DeleteOnSuccess, 600", job.RetentionPolicy, job.TimeoutSeconds)
+	}
+}